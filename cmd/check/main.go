@@ -16,7 +16,12 @@ func main() {
 		log.Fatalf("failed to parse input JSON: %s", err)
 	}
 
-	checkResponse, err := check.NewChecker(&request).Check()
+	checker, err := check.NewChecker(&request)
+	if err != nil {
+		log.Fatalf("failed to connect to Concourse: %s", err)
+	}
+
+	checkResponse, err := checker.Check()
 	if err != nil {
 		log.Fatalf("failed to perform 'check': %s", err)
 	}
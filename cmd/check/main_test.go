@@ -123,17 +123,29 @@ func TestCheckCmd(t *testing.T) {
 		when("version is not given (ie, first run) and initial_build_id is set", func() {
 			gt := gomega.NewGomegaWithT(t)
 			var session *gexec.Session
+			var server *ghttp.Server
 
 			it.Before(func() {
+				server = ghttp.NewServer()
+				server.RouteToHandler("GET", "/api/v1/builds/222", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+
+					json.NewEncoder(w).Encode(atc.Build{ID: 222, Status: string(atc.StatusSucceeded)})
+				}))
+
 				cmd := exec.Command(compiledPath)
-				input := `{"source":{"concourse_url":"https://ignored.example.com","initial_build_id": 222}}`
+				input := fmt.Sprintf(`{"source":{"concourse_url":"%s","initial_build_id": 222}}`, server.URL())
 				cmd.Stdin = bytes.NewBufferString(input)
 				session, err = gexec.Start(cmd, it.Out(), it.Out())
 				gt.Expect(err).NotTo(gomega.HaveOccurred())
 			})
 
+			it.After(func() {
+				server.Close()
+			})
+
 			it("returns the initial_build_id as the version", func() {
-				gt.Eventually(session.Out).Should(gbytes.Say(`[{"build_id":"222"}]`))
+				gt.Eventually(session.Out).Should(gbytes.Say(`\[{"build_id":"222","status":"succeeded"}\]`))
 				gt.Eventually(session).Should(gexec.Exit(0))
 			})
 		}, spec.Nested())
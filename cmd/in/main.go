@@ -33,7 +33,12 @@ func main() {
 	request.ReleaseGitRef = releaseGitRef
 	request.GetTimestamp = time.Now().UTC().Unix()
 
-	inResponse, err := in.NewInner(&request).In()
+	inner, err := in.NewInner(&request)
+	if err != nil {
+		log.Fatalf("failed to connect to Concourse: %s", err)
+	}
+
+	inResponse, err := inner.In()
 	if err != nil {
 		log.Fatalf("failed to perform 'in': %s", err)
 	}
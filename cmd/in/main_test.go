@@ -97,7 +97,7 @@ func TestInCmd(t *testing.T) {
 				})
 
 				it("prints metadata to stdout", func() {
-					gt.Eventually(session.Out).Should(gbytes.Say(`"metadata":\[{"name":"build_url","value":"http://127.0.0.1:(\d+)/teams/t/pipelines/p/jobs/j/builds/111"}\]`))
+					gt.Eventually(session.Out).Should(gbytes.Say(`"metadata":\[{"name":"build_url","value":"http://127.0.0.1:(\d+)/teams/t/pipelines/p/jobs/j/builds/111"},{"name":"team","value":"t"},`))
 					gt.Eventually(session).Should(gexec.Exit(0))
 				})
 			}, spec.Nested())
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/out"
+
+	"encoding/json"
+	"log"
+	"os"
+)
+
+func main() {
+	var request config.OutRequest
+	err := json.NewDecoder(os.Stdin).Decode(&request)
+	if err != nil {
+		log.Fatalf("failed to parse input JSON: %s", err)
+	}
+
+	if len(os.Args) > 1 {
+		request.WorkingDirectory = os.Args[1]
+	}
+
+	outer, err := out.NewOuter(&request)
+	if err != nil {
+		log.Fatalf("failed to connect to Concourse: %s", err)
+	}
+
+	outResponse, err := outer.Out()
+	if err != nil {
+		log.Fatalf("failed to perform 'out': %s", err)
+	}
+
+	err = json.NewEncoder(os.Stdout).Encode(outResponse)
+	if err != nil {
+		log.Fatalf("failed to encode out.Out response: %s", err.Error())
+	}
+}
@@ -0,0 +1,136 @@
+package main_test
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"github.com/concourse/atc"
+
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+)
+
+func TestOutCmd(t *testing.T) {
+	gt := gomega.NewGomegaWithT(t)
+
+	compiledPath, err := gexec.Build("github.com/jchesterpivotal/concourse-build-resource/cmd/out")
+	if err != nil {
+		gt.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	spec.Run(t, "/opt/resource/out", func(t *testing.T, when spec.G, it spec.S) {
+		when("given malformed JSON", func() {
+			gt := gomega.NewGomegaWithT(t)
+			var session *gexec.Session
+
+			it.Before(func() {
+				cmd := exec.Command(compiledPath, "sources")
+				cmd.Stdin = bytes.NewBufferString(`} this is malformed[] JSON:`)
+				session, err = gexec.Start(cmd, it.Out(), it.Out())
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("fails with an error", func() {
+				gt.Eventually(session.Err).Should(gbytes.Say("failed to parse input JSON:"))
+				gt.Eventually(session).Should(gexec.Exit(1))
+			})
+		}, spec.Nested())
+
+		when("given a trigger action", func() {
+			gt := gomega.NewGomegaWithT(t)
+			var session *gexec.Session
+			var server *ghttp.Server
+			var triggered bool
+
+			it.Before(func() {
+				server = ghttp.NewServer()
+				server.RouteToHandler("POST", "/api/v1/teams/t/pipelines/p/jobs/j/builds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					triggered = true
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode(atc.Build{
+						ID:           999,
+						Name:         "111",
+						Status:       string(atc.StatusPending),
+						TeamName:     "t",
+						PipelineName: "p",
+						JobName:      "j",
+					})
+				}))
+
+				cmd := exec.Command(compiledPath, "sources")
+				input := fmt.Sprintf(`{"params":{"action":"trigger"},"source":{"concourse_url":"%s","team":"t","pipeline":"p","job":"j"}}`, server.URL())
+				cmd.Stdin = bytes.NewBufferString(input)
+				session, err = gexec.Start(cmd, it.Out(), it.Out())
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it.After(func() {
+				server.Close()
+			})
+
+			it("triggers the job", func() {
+				gt.Eventually(session).Should(gexec.Exit(0))
+				gt.Expect(triggered).To(gomega.BeTrue())
+			})
+
+			it("prints the new build as the version to stdout", func() {
+				gt.Eventually(session.Out).Should(gbytes.Say(`"version":{"build_id":"999","team":"t","pipeline":"p","job":"j","job_number":"111"}`))
+				gt.Eventually(session).Should(gexec.Exit(0))
+			})
+		}, spec.Nested())
+
+		when("given a trigger action which waits for the build", func() {
+			gt := gomega.NewGomegaWithT(t)
+			var session *gexec.Session
+			var server *ghttp.Server
+
+			it.Before(func() {
+				server = ghttp.NewServer()
+				server.RouteToHandler("POST", "/api/v1/teams/t/pipelines/p/jobs/j/builds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode(atc.Build{ID: 999, Name: "111", Status: string(atc.StatusPending), TeamName: "t", PipelineName: "p", JobName: "j"})
+				}))
+				server.RouteToHandler("GET", "/api/v1/builds/999/events", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "text/event-stream")
+					io.WriteString(w, "id: 0\nevent: event\ndata: {\"event\":\"log\",\"version\":\"5.1\",\"data\":{\"payload\":\"hello from the remote build\\n\"}}\n\n")
+					io.WriteString(w, "id: 1\nevent: event\ndata: {\"event\":\"status\",\"version\":\"1.0\",\"data\":{\"status\":\"failed\"}}\n\n")
+					io.WriteString(w, "id: 2\nevent: end\ndata\n\n")
+				}))
+				server.RouteToHandler("GET", "/api/v1/builds/999", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode(atc.Build{ID: 999, Name: "111", Status: string(atc.StatusFailed), TeamName: "t", PipelineName: "p", JobName: "j"})
+				}))
+
+				cmd := exec.Command(compiledPath, "sources")
+				input := fmt.Sprintf(`{"params":{"action":"trigger","wait":true},"source":{"concourse_url":"%s","team":"t","pipeline":"p","job":"j"}}`, server.URL())
+				cmd.Stdin = bytes.NewBufferString(input)
+				session, err = gexec.Start(cmd, it.Out(), it.Out())
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it.After(func() {
+				server.Close()
+			})
+
+			it("streams the build's logs to stderr", func() {
+				gt.Eventually(session.Err).Should(gbytes.Say("hello from the remote build"))
+			})
+
+			it("fails because the build failed", func() {
+				gt.Eventually(session.Err).Should(gbytes.Say(`finished with status 'failed'`))
+				gt.Eventually(session).Should(gexec.Exit(1))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+
+	gexec.CleanupBuildArtifacts()
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"path/filepath"
 	"fmt"
@@ -10,9 +11,13 @@ import (
 )
 
 func main() {
+	// --html prints events.html as it is, so that it can be saved to a file
+	html := flag.Bool("html", false, "print events.html instead of the log")
+	flag.Parse()
+
 	var jsonpath, cleanpath string
-	if len(os.Args) > 1 {
-		jsonpath = os.Args[1]
+	if flag.NArg() > 0 {
+		jsonpath = flag.Arg(0)
 
 		cleanpath = filepath.Clean(jsonpath)
 		if strings.HasPrefix(cleanpath, "/") ||
@@ -20,10 +25,28 @@ func main() {
 			strings.Count(cleanpath, "/") > 1 {
 			log.Fatalf("malformed path")
 		}
-
-		cleanpath = filepath.Join(cleanpath, "events.log")
 	} else {
-		cleanpath = filepath.Join("build", "events.log")
+		cleanpath = "build"
+	}
+
+	if *html {
+		htmlpath := filepath.Join(cleanpath, "events.html")
+		contents, err := ioutil.ReadFile(htmlpath)
+		if err != nil {
+			log.Fatalf("could not open %s: %s", htmlpath, err.Error())
+		}
+
+		fmt.Print(string(contents))
+		return
+	}
+
+	// a get with plain_log_only writes only the plain log
+	plainpath := filepath.Join(cleanpath, "events.plain.log")
+	cleanpath = filepath.Join(cleanpath, "events.log")
+	if _, err := os.Stat(cleanpath); os.IsNotExist(err) {
+		if _, err := os.Stat(plainpath); err == nil {
+			cleanpath = plainpath
+		}
 	}
 
 	contents, err := ioutil.ReadFile(cleanpath)
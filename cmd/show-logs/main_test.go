@@ -8,6 +8,7 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"os/exec"
@@ -41,6 +42,16 @@ func TestShowLogs(t *testing.T) {
 		gt.Expect(err).NotTo(gomega.MatchError("wrappers: file exists"))
 	}
 
+	err = os.Mkdir("plain", os.ModeDir|os.ModePerm)
+	if err != nil {
+		gt.Expect(err).NotTo(gomega.MatchError("plain: file exists"))
+	}
+
+	err = os.Mkdir("html", os.ModeDir|os.ModePerm)
+	if err != nil {
+		gt.Expect(err).NotTo(gomega.MatchError("html: file exists"))
+	}
+
 	spec.Run(t, "show-logs", func(t *testing.T, when spec.G, it spec.S) {
 		when("a resource name is given", func() {
 			gt = gomega.NewGomegaWithT(t)
@@ -67,6 +78,27 @@ log line the 2nd
 				})
 			}, spec.Nested())
 
+			when("the directory contains only an events.plain.log", func() {
+				var session *gexec.Session
+
+				it.Before(func() {
+					logfile, err := os.Create(filepath.Join("plain", "events.plain.log"))
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					_, err = logfile.WriteString("plain/events.plain.log log line 1\n")
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					cmd := exec.Command(compiledPath, "plain")
+					session, err = gexec.Start(cmd, it.Out(), it.Out())
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("prints the plain logs to stdout", func() {
+					gt.Eventually(session.Out).Should(gbytes.Say(`plain/events.plain.log log line 1`))
+					gt.Eventually(session).Should(gexec.Exit(0))
+				})
+			}, spec.Nested())
+
 			when("the directory exists but does not contain an events.log", func() {
 				var session *gexec.Session
 
@@ -131,6 +163,43 @@ log line the 2nd
 
 		}, spec.Nested())
 
+		when("--html is given", func() {
+			gt = gomega.NewGomegaWithT(t)
+
+			when("the directory contains an events.html", func() {
+				var session *gexec.Session
+
+				it.Before(func() {
+					err := ioutil.WriteFile(filepath.Join("html", "events.html"), []byte("<html>html/events.html</html>\n"), os.ModePerm)
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					cmd := exec.Command(compiledPath, "--html", "html")
+					session, err = gexec.Start(cmd, it.Out(), it.Out())
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("prints the HTML to stdout, without 'begin' and 'end' lines", func() {
+					gt.Eventually(session).Should(gexec.Exit(0))
+					gt.Expect(string(session.Out.Contents())).To(gomega.Equal("<html>html/events.html</html>\n"))
+				})
+			}, spec.Nested())
+
+			when("the directory does not contain an events.html", func() {
+				var session *gexec.Session
+
+				it.Before(func() {
+					cmd := exec.Command(compiledPath, "--html", "empty")
+					session, err = gexec.Start(cmd, it.Out(), it.Out())
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("prints a failure message and exits 1", func() {
+					gt.Eventually(session.Err).Should(gbytes.Say("could not open empty/events.html"))
+					gt.Eventually(session).Should(gexec.Exit(1))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
 		when("printing any events.log file", func() {
 			gt = gomega.NewGomegaWithT(t)
 			var session *gexec.Session
@@ -160,4 +229,6 @@ log line the 2nd
 	gt.Expect(os.RemoveAll("successful")).To(gomega.Succeed())
 	gt.Expect(os.RemoveAll("empty")).To(gomega.Succeed())
 	gt.Expect(os.RemoveAll("wrappers")).To(gomega.Succeed())
+	gt.Expect(os.RemoveAll("plain")).To(gomega.Succeed())
+	gt.Expect(os.RemoveAll("html")).To(gomega.Succeed())
 }
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/timings"
+
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	var filepath string
+	if len(os.Args) > 1 {
+		filepath = fmt.Sprintf("%s/timings.json", os.Args[1])
+	} else {
+		filepath = "build/timings.json"
+	}
+
+	table, err := timings.Table(filepath)
+	if err != nil {
+		log.Fatalf("could not show %s: %s", filepath, err.Error())
+	}
+
+	fmt.Print(table)
+}
@@ -0,0 +1,77 @@
+package main_test
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+func TestShowTimings(t *testing.T) {
+	gt := gomega.NewGomegaWithT(t)
+
+	compiledPath, err := gexec.Build("github.com/jchesterpivotal/concourse-build-resource/cmd/show-timings")
+	if err != nil {
+		gt.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	err = os.Mkdir("timed", os.ModeDir|os.ModePerm)
+	if err != nil {
+		gt.Expect(err).NotTo(gomega.MatchError("timed: file exists"))
+	}
+
+	err = os.Mkdir("empty", os.ModeDir|os.ModePerm)
+	if err != nil {
+		gt.Expect(err).NotTo(gomega.MatchError("empty: file exists"))
+	}
+
+	spec.Run(t, "show-timings", func(t *testing.T, when spec.G, it spec.S) {
+		gt = gomega.NewGomegaWithT(t)
+		var session *gexec.Session
+
+		when("the directory contains a timings.json", func() {
+			it.Before(func() {
+				err := ioutil.WriteFile(filepath.Join("timed", "timings.json"), []byte(`{
+					"concourse_build_resource": {"release": "test"},
+					"build": {"start_time": 1535803200, "end_time": 1535803300, "duration_seconds": 100},
+					"steps": [{"id": "t1", "kind": "task", "name": "unit", "start_time": 1535803210, "end_time": 1535803260, "duration_seconds": 50, "exit_status": 0}]
+				}`), os.ModePerm)
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				cmd := exec.Command(compiledPath, "timed")
+				session, err = gexec.Start(cmd, it.Out(), it.Out())
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("prints a table of the timings to stdout", func() {
+				gt.Eventually(session.Out).Should(gbytes.Say(`STEP\s+STARTED\s+DURATION`))
+				gt.Eventually(session.Out).Should(gbytes.Say(`task: unit\s+\+00:00:10\s+00:00:50\s+50\.0.\s+0`))
+				gt.Eventually(session).Should(gexec.Exit(0))
+			})
+		}, spec.Nested())
+
+		when("the directory does not contain a timings.json", func() {
+			it.Before(func() {
+				cmd := exec.Command(compiledPath, "empty")
+				session, err = gexec.Start(cmd, it.Out(), it.Out())
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("prints a failure message and exits 1", func() {
+				gt.Eventually(session.Err).Should(gbytes.Say("could not show empty/timings.json"))
+				gt.Eventually(session).Should(gexec.Exit(1))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+
+	gexec.CleanupBuildArtifacts()
+	gt.Expect(os.RemoveAll("timed")).To(gomega.Succeed())
+	gt.Expect(os.RemoveAll("empty")).To(gomega.Succeed())
+}
@@ -68,7 +68,7 @@ require (
 	github.com/vito/go-sse v0.0.0-20160212001227-fd69d275caac // indirect
 	golang.org/x/crypto v0.0.0-20180820150726-614d502a4dac // indirect
 	golang.org/x/net v0.0.0-20180816102801-aaf60122140d // indirect
-	golang.org/x/oauth2 v0.0.0-20180821212333-d2e6202438be // indirect
+	golang.org/x/oauth2 v0.0.0-20180821212333-d2e6202438be
 	golang.org/x/sync v0.0.0-20180314180146-1d60e4601c6f // indirect
 	golang.org/x/sys v0.0.0-20180816055513-1c9583448a9c // indirect
 	golang.org/x/text v0.3.0 // indirect
@@ -0,0 +1,33 @@
+package buildevents
+
+import (
+	"github.com/concourse/atc"
+	gc "github.com/concourse/go-concourse/concourse"
+
+	"fmt"
+)
+
+// Open streams the build's events. It is false when the credentials in source are not authorized to see them,
+// which is not treated as an error. Concourse refuses anonymous requests as "not authorized" and logged-in ones,
+// such as for a private job in another team's public pipeline, as "forbidden". The event stream of a running build
+// stays open until the build finishes.
+func Open(client gc.Client, buildId string) (gc.Events, bool, error) {
+	events, err := client.BuildEvents(buildId)
+	if err != nil && (err.Error() == "not authorized" || err.Error() == "forbidden") {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error while fetching events for build '%s': %s", buildId, err.Error())
+	}
+
+	return events, true, nil
+}
+
+// SoFar reads only the events the build has produced so far, rather than waiting for a running build to finish.
+func SoFar(events gc.Events, build atc.Build) gc.Events {
+	if build.Status == string(atc.StatusStarted) || build.Status == string(atc.StatusPending) {
+		return newRunningBuildEvents(events, runningBuildQuietPeriod)
+	}
+
+	return events
+}
@@ -0,0 +1,53 @@
+package buildevents
+
+import (
+	"github.com/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"errors"
+)
+
+func TestOpen(t *testing.T) {
+	spec.Run(t, "Open", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var fakeclient *concoursefakes.FakeClient
+
+		it.Before(func() {
+			fakeclient = new(concoursefakes.FakeClient)
+		})
+
+		when("the events are not authorized", func() {
+			it("is false, without an error", func() {
+				fakeclient.BuildEventsReturns(nil, errors.New("not authorized"))
+
+				events, authorized, err := Open(fakeclient, "999")
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				gt.Expect(authorized).To(gomega.BeFalse())
+				gt.Expect(events).To(gomega.BeNil())
+			})
+		}, spec.Nested())
+
+		when("the events are forbidden to the user logged in", func() {
+			it("is false, without an error", func() {
+				fakeclient.BuildEventsReturns(nil, errors.New("forbidden"))
+
+				events, authorized, err := Open(fakeclient, "999")
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				gt.Expect(authorized).To(gomega.BeFalse())
+				gt.Expect(events).To(gomega.BeNil())
+			})
+		}, spec.Nested())
+
+		when("fetching the events fails", func() {
+			it("returns an error", func() {
+				fakeclient.BuildEventsReturns(nil, errors.New("connection refused"))
+
+				_, _, err := Open(fakeclient, "999")
+				gt.Expect(err).To(gomega.MatchError("error while fetching events for build '999': connection refused"))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,65 @@
+package buildevents
+
+import (
+	"github.com/concourse/atc"
+	gc "github.com/concourse/go-concourse/concourse"
+
+	"io"
+	"time"
+)
+
+// runningBuildQuietPeriod is how long to wait for another event from a running build before deciding that
+// everything it has produced so far has been read.
+var runningBuildQuietPeriod = 3 * time.Second
+
+type eventResult struct {
+	event atc.Event
+	err   error
+}
+
+// runningBuildEvents reads the events that a running build has produced so far. The event stream of a running
+// build stays open until the build finishes, so once no event arrives within the quiet period, the stream is
+// closed and reading ends as if it had reached the end.
+type runningBuildEvents struct {
+	events      gc.Events
+	quietPeriod time.Duration
+	results     chan eventResult
+	done        bool
+}
+
+func newRunningBuildEvents(events gc.Events, quietPeriod time.Duration) *runningBuildEvents {
+	return &runningBuildEvents{
+		events:      events,
+		quietPeriod: quietPeriod,
+		results:     make(chan eventResult, 1),
+	}
+}
+
+func (r *runningBuildEvents) NextEvent() (atc.Event, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	go func() {
+		ev, err := r.events.NextEvent()
+		r.results <- eventResult{event: ev, err: err}
+	}()
+
+	select {
+	case result := <-r.results:
+		return result.event, result.err
+	case <-time.After(r.quietPeriod):
+		r.done = true
+		r.events.Close()
+		return nil, io.EOF
+	}
+}
+
+func (r *runningBuildEvents) Close() error {
+	if r.done {
+		return nil
+	}
+
+	r.done = true
+	return r.events.Close()
+}
@@ -0,0 +1,75 @@
+package buildevents
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/go-concourse/concourse/eventstream/eventstreamfakes"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"io"
+	"time"
+)
+
+func TestRunningBuildEvents(t *testing.T) {
+	spec.Run(t, "runningBuildEvents", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var stream *eventstreamfakes.FakeEventStream
+		var closed chan struct{}
+
+		it.Before(func() {
+			stream = &eventstreamfakes.FakeEventStream{}
+			closed = make(chan struct{})
+			produced := []atc.Event{event.Log{Payload: "first"}, event.Log{Payload: "second"}}
+			stream.NextEventStub = func() (atc.Event, error) {
+				if len(produced) == 0 {
+					<-closed // a running build's stream blocks until it is closed
+					return nil, io.ErrUnexpectedEOF
+				}
+
+				next := produced[0]
+				produced = produced[1:]
+				return next, nil
+			}
+			stream.CloseStub = func() error {
+				close(closed)
+				return nil
+			}
+		})
+
+		when("the build has produced some events so far", func() {
+			it("returns them, then ends once no more arrive", func() {
+				events := newRunningBuildEvents(stream, 10*time.Millisecond)
+
+				ev, err := events.NextEvent()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				gt.Expect(ev).To(gomega.Equal(event.Log{Payload: "first"}))
+
+				ev, err = events.NextEvent()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				gt.Expect(ev).To(gomega.Equal(event.Log{Payload: "second"}))
+
+				_, err = events.NextEvent()
+				gt.Expect(err).To(gomega.Equal(io.EOF))
+				gt.Expect(stream.CloseCallCount()).To(gomega.Equal(1))
+			})
+
+			it("keeps returning the end of the stream without closing it again", func() {
+				events := newRunningBuildEvents(stream, 10*time.Millisecond)
+				for {
+					_, err := events.NextEvent()
+					if err == io.EOF {
+						break
+					}
+				}
+
+				_, err := events.NextEvent()
+				gt.Expect(err).To(gomega.Equal(io.EOF))
+				gt.Expect(events.Close()).To(gomega.Succeed())
+				gt.Expect(stream.CloseCallCount()).To(gomega.Equal(1))
+			})
+		})
+	}, spec.Report(report.Terminal{}))
+}
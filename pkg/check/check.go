@@ -1,6 +1,7 @@
 package check
 
 import (
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/client"
 	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
 	"log"
 	"strings"
@@ -8,9 +9,8 @@ import (
 	"github.com/concourse/atc"
 	gc "github.com/concourse/go-concourse/concourse"
 
-	"crypto/tls"
 	"fmt"
-	"net/http"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -23,10 +23,27 @@ type checker struct {
 	checkRequest    *config.CheckRequest
 	concourseClient gc.Client
 	concourseTeam   gc.Team
+	exclusions      exclusions
+}
+
+type exclusions struct {
+	pipelines []namePattern
+	jobs      []namePattern
+	teams     []namePattern
 }
 
 const defaultVersionPageSize = 100
 
+// maxLookBackPages is how many pages of builds the first check looks through for a wanted build before giving up.
+const maxLookBackPages = 10
+
+// Settings for one_off_builds, which are builds created by fly execute rather than by a job.
+const (
+	oneOffBuildsInclude = "include"
+	oneOffBuildsExclude = "exclude"
+	oneOffBuildsOnly    = "only"
+)
+
 func (c checker) Check() (*config.CheckResponse, error) {
 	if c.checkRequest.Source.EnableTracing {
 		log.Printf("Received CheckRequest: %+v", c.checkRequest)
@@ -39,8 +56,65 @@ func (c checker) Check() (*config.CheckResponse, error) {
 		versionPageSize = defaultVersionPageSize
 	}
 
+	err := c.validateStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.validateOneOffBuilds()
+	if err != nil {
+		return nil, err
+	}
+
+	c.exclusions, err = c.compileExclusions()
+	if err != nil {
+		return nil, err
+	}
+
+	initialBuildSince, err := c.parseInitialBuildSince()
+	if err != nil {
+		return nil, err
+	}
+
+	if initialBuildId > 0 && !initialBuildSince.IsZero() {
+		return nil, fmt.Errorf("only one of initial_build_id or initial_build_since can be set")
+	}
+
 	if version.BuildId == "" && initialBuildId > 0 {
-		return &config.CheckResponse{{BuildId: strconv.Itoa(initialBuildId)}}, nil
+		build, found, err := c.concourseClient.Build(strconv.Itoa(initialBuildId))
+		if err != nil {
+			return nil, fmt.Errorf("error while fetching initial_build_id '%d': %s", initialBuildId, err.Error())
+		}
+		if !found {
+			return nil, fmt.Errorf("server could not find initial_build_id '%d'", initialBuildId)
+		}
+
+		return &config.CheckResponse{c.versionOf(build)}, nil
+	}
+
+	if version.BuildId == "" && !initialBuildSince.IsZero() {
+		build, found, err := c.getEarliestWantedBuildSince(initialBuildSince, versionPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return &config.CheckResponse{}, nil
+		}
+
+		return &config.CheckResponse{c.versionOf(build)}, nil
+	}
+
+	// the most recent build might not be wanted, so we may need to look further back
+	if version.BuildId == "" && c.isFiltering() {
+		build, found, err := c.getLatestWantedBuild(versionPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return &config.CheckResponse{}, nil
+		}
+
+		return &config.CheckResponse{c.versionOf(build)}, nil
 	}
 
 	if version.BuildId == "" {
@@ -49,9 +123,26 @@ func (c checker) Check() (*config.CheckResponse, error) {
 			return nil, err
 		}
 
-		buildId := strconv.Itoa(builds[0].ID)
+		if len(builds) == 0 { // there are no builds at all
+			return &config.CheckResponse{}, nil
+		}
+
+		// the latest build may still be running, or be a one-off build, in which case the latest finished build
+		// is used instead
+		if !c.isWanted(builds[0]) {
+			build, found, err := c.getLatestWantedBuild(versionPageSize)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return &config.CheckResponse{}, nil
+			}
+
+			return &config.CheckResponse{c.versionOf(build)}, nil
+		}
+
 		return &config.CheckResponse{
-			config.Version{BuildId: buildId},
+			c.versionOf(builds[0]),
 		}, nil
 	}
 
@@ -60,7 +151,18 @@ func (c checker) Check() (*config.CheckResponse, error) {
 		return nil, fmt.Errorf("could not convert build id '%s' to an int: '%s", version.BuildId, err.Error())
 	}
 
-	builds, err := c.getBuilds(gc.Page{Since: buildId, Limit: versionPageSize})
+	if buildId < 1 {
+		return nil, fmt.Errorf("build id '%s' is not a valid build ID, which start at 1", version.BuildId)
+	}
+
+	var builds []atc.Build
+	if buildId > 1 {
+		// Until gives the builds after the one given, so starting one before includes the version's own build,
+		// which is how a build that was started last time gets looked at again once it finishes
+		builds, err = c.getBuilds(gc.Page{Until: buildId - 1, Limit: versionPageSize})
+	} else {
+		builds, err = c.getBuildsFromFirst(versionPageSize)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -70,30 +172,53 @@ func (c checker) Check() (*config.CheckResponse, error) {
 	}
 
 	newBuilds := make(config.CheckResponse, 0)
-	for _, b := range builds {
-		if b.Status != string(atc.StatusStarted) && b.Status != string(atc.StatusPending) {
-			newBuildId := strconv.Itoa(b.ID)
-			newBuilds = append(newBuilds, config.Version{BuildId: newBuildId})
+	for i, b := range builds {
+		// an unfinished build only holds back up to a page of builds after it, so that a build which is stuck, or
+		// taking a long time, can't stop check from moving on
+		holdsBack := len(builds)-i-1 < versionPageSize
+
+		// a pending build becomes a started version once it runs, so later builds wait for it in the same way
+		if b.Status == string(atc.StatusPending) && c.checkRequest.Source.IncludeRunning && holdsBack {
+			started := b
+			started.Status = string(atc.StatusStarted)
+			if c.isWanted(started) {
+				break
+			}
+		}
+
+		if !c.isWanted(b) {
+			continue
+		}
+
+		// the version's own build is given back unchanged, as out, or an earlier release, may have described it
+		// differently, which Concourse would take to be a new version
+		v := c.versionOf(b)
+		if b.ID == buildId && v.Phase == version.Phase {
+			v = version
+		}
+		newBuilds = append(newBuilds, v)
+
+		// the next check starts from the last version, so stopping at a running build means it is looked at again
+		// until it finishes, even if later builds finish first
+		if b.Status == string(atc.StatusStarted) && holdsBack {
+			break
 		}
 	}
 
-	if len(newBuilds) == 0 { // there were no new builds
+	if len(newBuilds) == 0 { // there were no new builds, or none that we want
 		return &config.CheckResponse{version}, nil
 	}
 
 	return &newBuilds, nil
 }
 
-func NewChecker(input *config.CheckRequest) Checker {
-	tr := &http.Transport{
-		MaxIdleConns:    10,
-		IdleConnTimeout: 30 * time.Second,
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+func NewChecker(input *config.CheckRequest) (Checker, error) {
+	concourse, err := client.New(input.Source)
+	if err != nil {
+		return nil, err
 	}
-	client := &http.Client{Transport: tr}
-	concourse := gc.NewClient(input.Source.ConcourseUrl, client, input.Source.EnableTracing)
 
-	return NewCheckerUsingClient(input, concourse)
+	return NewCheckerUsingClient(input, concourse), nil
 }
 
 func NewCheckerUsingClient(input *config.CheckRequest, client gc.Client) Checker {
@@ -106,125 +231,455 @@ func NewCheckerUsingClient(input *config.CheckRequest, client gc.Client) Checker
 	}
 }
 
+// buildScope is a pipeline, a job within a pipeline or, when both are empty, the whole team or Concourse.
+type buildScope struct {
+	pipeline string
+	job      string
+}
+
+// getScopes works out where to look for builds. Usually that's the single team, pipeline or job given
+// in source, but the pipelines and jobs lists can select many of them by name or pattern.
+func (c checker) getScopes() ([]buildScope, error) {
+	source := c.checkRequest.Source
+	if len(source.Pipelines) == 0 && len(source.Jobs) == 0 {
+		return []buildScope{{pipeline: source.Pipeline, job: source.Job}}, nil
+	}
+
+	if source.Team == "" {
+		return nil, fmt.Errorf("team must be set when using pipelines or jobs")
+	}
+
+	pipelinePatterns, err := compilePatterns("pipelines", namesAndPatterns(source.Pipeline, source.Pipelines))
+	if err != nil {
+		return nil, err
+	}
+
+	jobPatterns, err := compilePatterns("jobs", namesAndPatterns(source.Job, source.Jobs))
+	if err != nil {
+		return nil, err
+	}
+
+	pipelines, err := c.concourseTeam.ListPipelines()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve pipelines for team '%s': %s", source.Team, err.Error())
+	}
+
+	scopes := make([]buildScope, 0)
+	for _, p := range pipelines {
+		if len(pipelinePatterns) > 0 && !matchesAny(pipelinePatterns, p.Name) {
+			continue
+		}
+		if matchesAny(c.exclusions.pipelines, p.Name) {
+			continue
+		}
+
+		if len(jobPatterns) == 0 {
+			scopes = append(scopes, buildScope{pipeline: p.Name})
+			continue
+		}
+
+		jobs, err := c.concourseTeam.ListJobs(p.Name)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve jobs for pipeline '%s': %s", p.Name, err.Error())
+		}
+
+		for _, j := range jobs {
+			if matchesAny(jobPatterns, j.Name) && !matchesAny(c.exclusions.jobs, j.Name) {
+				scopes = append(scopes, buildScope{pipeline: p.Name, job: j.Name})
+			}
+		}
+	}
+
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("no pipelines or jobs in team '%s' matched the pipelines and jobs given in source", source.Team)
+	}
+
+	return scopes, nil
+}
+
+func namesAndPatterns(name string, patterns []string) []string {
+	if name == "" {
+		return patterns
+	}
+
+	return append([]string{name}, patterns...)
+}
+
+// getBuilds merges builds from every scope, ordered by their global build ID.
 func (c checker) getBuilds(initialPage gc.Page) ([]atc.Build, error) {
+	scopes, err := c.getScopes()
+	if err != nil {
+		return nil, err
+	}
+
+	builds := make([]atc.Build, 0)
+	for _, scope := range scopes {
+		scopeBuilds, err := c.getBuildsForScope(scope, initialPage)
+		if err != nil {
+			return nil, err
+		}
+
+		builds = append(builds, scopeBuilds...)
+	}
+
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].ID < builds[j].ID
+	})
+
+	// latest version only case
+	if initialPage.Limit == 1 && len(builds) > 1 {
+		return builds[len(builds)-1:], nil
+	}
+
+	return builds, nil
+}
+
+// getBuildsFromFirst gets the builds since the very first build. go-concourse leaves out an Until of 0, which would
+// give the latest builds instead, so the builds after the first are paged through, and the first is fetched on its
+// own.
+func (c checker) getBuildsFromFirst(pageSize int) ([]atc.Build, error) {
+	builds, err := c.getBuilds(gc.Page{Until: 1, Limit: pageSize})
+	if err != nil {
+		return nil, err
+	}
+
+	first, found, err := c.concourseClient.Build("1")
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching build '1': %s", err.Error())
+	}
+	if !found {
+		return builds, nil
+	}
+
+	return append([]atc.Build{first}, builds...), nil
+}
+
+func (c checker) getBuildsForScope(scope buildScope, initialPage gc.Page) ([]atc.Build, error) {
+	// latest version only case
+	if initialPage.Limit == 1 {
+		builds, _, err := c.getBuildsPage(scope, initialPage)
+		if err != nil {
+			return nil, err
+		}
+
+		return builds, nil
+	}
+
+	// versions-since case. Each scope is paged forwards from the given build on its own, so a scope whose builds
+	// all came after it, such as a newly matched job, still has them all fetched
+	builds := make([]atc.Build, 0)
+	err := c.pageThroughBuilds(scope, initialPage, forwards, func(pageBuilds []atc.Build) bool {
+		builds = append(builds, pageBuilds...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return builds, nil
+}
+
+// forwards and backwards pick the page to fetch after the current one. Concourse lists the most recent builds first,
+// so Previous pages have newer builds and Next pages have older ones.
+func forwards(pagination gc.Pagination) *gc.Page {
+	return pagination.Previous
+}
+
+func backwards(pagination gc.Pagination) *gc.Page {
+	return pagination.Next
+}
+
+// pageThroughBuilds fetches pages of builds from the given page onwards, in the direction picked by next, until
+// visit returns false or there are no more pages.
+func (c checker) pageThroughBuilds(scope buildScope, page gc.Page, next func(gc.Pagination) *gc.Page, visit func([]atc.Build) bool) error {
+	for {
+		builds, pagination, err := c.getBuildsPage(scope, page)
+		if err != nil {
+			return err
+		}
+
+		if !visit(builds) {
+			return nil
+		}
+
+		nextPage := next(pagination)
+		if nextPage == nil {
+			return nil
+		}
+		page = *nextPage
+	}
+}
+
+// getBuildsPage fetches a single page of builds from whichever of the Concourse, team, pipeline or job
+// is the narrowest scope.
+func (c checker) getBuildsPage(scope buildScope, page gc.Page) ([]atc.Build, gc.Pagination, error) {
 	concourseUrl := c.checkRequest.Source.ConcourseUrl
 	team := c.checkRequest.Source.Team
-	pipeline := c.checkRequest.Source.Pipeline
-	job := c.checkRequest.Source.Job
-	builds := make([]atc.Build, 0)
-	pageBuilds := make([]atc.Build, 0)
+	pipeline := scope.pipeline
+	job := scope.job
+	var builds []atc.Build
 	var pagination gc.Pagination
 	var found bool
 	var err error
 
-	// latest version only case
-	if initialPage.Limit == 1 {
-		if job == "" && pipeline == "" && team == "" {
-			builds, _, err = c.concourseClient.Builds(initialPage)
-			if err != nil {
-				return nil, fmt.Errorf("could not retrieve builds for concourse URL '%s': %s", concourseUrl, err.Error())
-			}
-		} else if job == "" && pipeline == "" {
-			builds, _, err = c.concourseTeam.Builds(initialPage)
-			if err != nil {
-				return nil, fmt.Errorf("could not retrieve builds for team '%s': %s", team, err.Error())
-			}
-		} else if job == "" {
-			builds, _, found, err = c.concourseTeam.PipelineBuilds(pipeline, initialPage)
-			if err != nil {
-				return nil, fmt.Errorf("could not retrieve builds for pipeline '%s': %s", pipeline, err.Error())
-			}
-			if !found {
-				return nil, fmt.Errorf("server could not find pipeline '%s'", pipeline)
-			}
-		} else {
-			builds, _, found, err = c.concourseTeam.JobBuilds(pipeline, job, initialPage)
-			if err != nil {
-				return nil, fmt.Errorf("could not retrieve builds for pipeline/job '%s/%s': %s", pipeline, job, err.Error())
-			}
-			if !found {
-				return nil, fmt.Errorf("server could not find pipeline/job '%s/%s'", pipeline, job)
-			}
+	if job == "" && pipeline == "" && team == "" {
+		builds, pagination, err = c.concourseClient.Builds(page)
+		if err != nil {
+			return nil, gc.Pagination{}, fmt.Errorf("could not retrieve builds for concourse URL '%s': %s", concourseUrl, err.Error())
+		}
+	} else if job == "" && pipeline == "" {
+		builds, pagination, err = c.concourseTeam.Builds(page)
+		if err != nil {
+			return nil, gc.Pagination{}, fmt.Errorf("could not retrieve builds for team '%s': %s", team, err.Error())
+		}
+	} else if job == "" {
+		builds, pagination, found, err = c.concourseTeam.PipelineBuilds(pipeline, page)
+		if err != nil {
+			return nil, gc.Pagination{}, fmt.Errorf("could not retrieve builds for pipeline '%s': %s", pipeline, err.Error())
+		}
+		if !found {
+			return nil, gc.Pagination{}, fmt.Errorf("server could not find pipeline '%s'", pipeline)
 		}
 	} else {
-		// versions-since or initial_build_id cases
-		if job == "" && pipeline == "" && team == "" {
-			_, pagination, err = c.concourseClient.Builds(initialPage)
-			if err != nil {
-				return nil, fmt.Errorf("could not retrieve builds for concourse URL '%s': %s", concourseUrl, err.Error())
-			}
+		builds, pagination, found, err = c.concourseTeam.JobBuilds(pipeline, job, page)
+		if err != nil {
+			return nil, gc.Pagination{}, fmt.Errorf("could not retrieve builds for pipeline/job '%s/%s': %s", pipeline, job, err.Error())
+		}
+		if !found {
+			return nil, gc.Pagination{}, fmt.Errorf("server could not find pipeline/job '%s/%s'", pipeline, job)
+		}
+	}
 
-			for pagination.Previous != nil {
-				pageBuilds, pagination, err = c.concourseClient.Builds(*pagination.Previous)
-				if err != nil {
-					return nil, fmt.Errorf("could not retrieve builds for concourse URL '%s': %s", concourseUrl, err.Error())
-				}
+	return builds, pagination, nil
+}
 
-				builds = append(pageBuilds, builds...)
-			}
-		} else if job == "" && pipeline == "" {
-			_, pagination, err = c.concourseTeam.Builds(initialPage)
-			if err != nil {
-				return nil, fmt.Errorf("could not retrieve builds for team '%s': %s", team, err.Error())
-			}
+// isWanted is true for finished builds which have one of the statuses given in source, or any status if none
+// were given, and which don't belong to an excluded team, pipeline or job. With include_running, started builds
+// are wanted too, whatever the statuses.
+func (c checker) isWanted(build atc.Build) bool {
+	if build.Status == string(atc.StatusPending) {
+		return false
+	}
 
-			for pagination.Previous != nil {
-				pageBuilds, pagination, err = c.concourseTeam.Builds(*pagination.Previous)
-				if err != nil {
-					return nil, fmt.Errorf("could not retrieve builds for concourse URL '%s': %s", concourseUrl, err.Error())
-				}
+	running := build.Status == string(atc.StatusStarted)
+	if running && !c.checkRequest.Source.IncludeRunning {
+		return false
+	}
 
-				builds = append(pageBuilds, builds...)
-			}
-		} else if job == "" {
-			_, pagination, found, err = c.concourseTeam.PipelineBuilds(pipeline, initialPage)
-			if err != nil {
-				return nil, fmt.Errorf("could not retrieve builds for pipeline '%s': %s", pipeline, err.Error())
-			}
-			if !found {
-				return nil, fmt.Errorf("server could not find pipeline '%s'", pipeline)
-			}
+	if !c.matchesOneOffBuilds(build) {
+		return false
+	}
+
+	if matchesAny(c.exclusions.teams, build.TeamName) ||
+		matchesAny(c.exclusions.pipelines, build.PipelineName) ||
+		matchesAny(c.exclusions.jobs, build.JobName) {
+		return false
+	}
+
+	statuses := c.checkRequest.Source.Statuses
+	if len(statuses) == 0 || running {
+		return true
+	}
+
+	for _, status := range statuses {
+		if build.Status == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesOneOffBuilds is true when one_off_builds lets the build through. Jobs never have one-off builds.
+func (c checker) matchesOneOffBuilds(build atc.Build) bool {
+	if c.checksOnlyJobs() {
+		return true
+	}
+
+	switch c.checkRequest.Source.OneOffBuilds {
+	case oneOffBuildsInclude:
+		return true
+	case oneOffBuildsOnly:
+		return build.OneOff()
+	default:
+		return !build.OneOff()
+	}
+}
+
+// versionOf describes the build for the Concourse UI, adding its phase when include_running is set.
+func (c checker) versionOf(build atc.Build) config.Version {
+	return config.VersionOf(build, c.checkRequest.Source.IncludeRunning)
+}
+
+// checksOnlyJobs is true when every scope is a job, whose builds are never one-off builds.
+func (c checker) checksOnlyJobs() bool {
+	return c.checkRequest.Source.Job != "" || len(c.checkRequest.Source.Jobs) > 0
+}
+
+// isFiltering is true when some builds might not be wanted for reasons other than being unfinished or being
+// one-off builds, which only the latest build needs to be checked for.
+func (c checker) isFiltering() bool {
+	return len(c.checkRequest.Source.Statuses) > 0 ||
+		len(c.exclusions.pipelines) > 0 ||
+		len(c.exclusions.jobs) > 0 ||
+		len(c.exclusions.teams) > 0
+}
+
+func (c checker) compileExclusions() (exclusions, error) {
+	var compiled exclusions
+	var err error
+	source := c.checkRequest.Source
+
+	compiled.pipelines, err = compilePatterns("exclude_pipelines", source.ExcludePipelines)
+	if err != nil {
+		return exclusions{}, err
+	}
+
+	compiled.jobs, err = compilePatterns("exclude_jobs", source.ExcludeJobs)
+	if err != nil {
+		return exclusions{}, err
+	}
+
+	compiled.teams, err = compilePatterns("exclude_teams", source.ExcludeTeams)
+	if err != nil {
+		return exclusions{}, err
+	}
+
+	return compiled, nil
+}
+
+func (c checker) validateOneOffBuilds() error {
+	switch c.checkRequest.Source.OneOffBuilds {
+	case "", oneOffBuildsInclude, oneOffBuildsExclude, oneOffBuildsOnly:
+		return nil
+	default:
+		return fmt.Errorf("unknown one_off_builds setting '%s', expected 'include', 'exclude' or 'only'", c.checkRequest.Source.OneOffBuilds)
+	}
+}
+
+func (c checker) validateStatuses() error {
+	for _, status := range c.checkRequest.Source.Statuses {
+		switch atc.BuildStatus(status) {
+		case atc.StatusSucceeded, atc.StatusFailed, atc.StatusErrored, atc.StatusAborted:
+		default:
+			return fmt.Errorf("unknown status '%s' in statuses, expected 'succeeded', 'failed', 'errored' or 'aborted'", status)
+		}
+	}
+
+	return nil
+}
+
+// getLatestWantedBuild finds the most recent build that isWanted, across all scopes.
+func (c checker) getLatestWantedBuild(pageSize int) (atc.Build, bool, error) {
+	scopes, err := c.getScopes()
+	if err != nil {
+		return atc.Build{}, false, err
+	}
 
-			for pagination.Previous != nil {
-				pageBuilds, pagination, found, err = c.concourseTeam.PipelineBuilds(pipeline, *pagination.Previous)
-				if err != nil {
-					return nil, fmt.Errorf("could not retrieve builds for concourse URL '%s': %s", concourseUrl, err.Error())
-				}
-				if !found {
-					return nil, fmt.Errorf("server could not find pipeline '%s'", pipeline)
-				}
+	var latest atc.Build
+	var found bool
+	for _, scope := range scopes {
+		build, scopeFound, err := c.getLatestWantedBuildForScope(scope, pageSize)
+		if err != nil {
+			return atc.Build{}, false, err
+		}
+
+		if scopeFound && (!found || build.ID > latest.ID) {
+			latest = build
+			found = true
+		}
+	}
+
+	return latest, found, nil
+}
+
+// getLatestWantedBuildForScope pages backwards from the most recent build until it finds one that isWanted, giving
+// up after maxLookBackPages pages.
+func (c checker) getLatestWantedBuildForScope(scope buildScope, pageSize int) (atc.Build, bool, error) {
+	var latest atc.Build
+	var found bool
+
+	looked := 0
+	err := c.walkBackThroughBuilds(scope, pageSize, func(build atc.Build) bool {
+		if c.isWanted(build) {
+			latest = build
+			found = true
+			return false
+		}
+
+		looked++
+		return looked < pageSize*maxLookBackPages
+	})
+
+	return latest, found, err
+}
+
+// getEarliestWantedBuildSince finds the earliest build that isWanted and started at or after the given time,
+// across all scopes.
+func (c checker) getEarliestWantedBuildSince(since time.Time, pageSize int) (atc.Build, bool, error) {
+	scopes, err := c.getScopes()
+	if err != nil {
+		return atc.Build{}, false, err
+	}
 
-				builds = append(pageBuilds, builds...)
+	var earliest atc.Build
+	var found bool
+	for _, scope := range scopes {
+		err := c.walkBackThroughBuilds(scope, pageSize, func(build atc.Build) bool {
+			// builds which are pending, or were aborted before they started, have no start time to compare
+			if build.StartTime == 0 {
+				return true
 			}
-		} else {
-			_, pagination, found, err = c.concourseTeam.JobBuilds(pipeline, job, initialPage)
-			if err != nil {
-				return nil, fmt.Errorf("could not retrieve builds for pipeline/job '%s/%s': %s", pipeline, job, err.Error())
+
+			if time.Unix(build.StartTime, 0).Before(since) {
+				return false
 			}
-			if !found {
-				return nil, fmt.Errorf("server could not find pipeline/job '%s/%s'", pipeline, job)
+
+			if c.isWanted(build) && (!found || build.ID < earliest.ID) {
+				earliest = build
+				found = true
 			}
 
-			for pagination.Previous != nil {
-				pageBuilds, pagination, found, err = c.concourseTeam.JobBuilds(pipeline, job, *pagination.Previous)
-				if err != nil {
-					return nil, fmt.Errorf("could not retrieve builds for pipeline/job '%s/%s': %s", pipeline, job, err.Error())
-				}
-				if !found {
-					return nil, fmt.Errorf("server could not find pipeline/job '%s/%s'", pipeline, job)
-				}
+			return true
+		})
+		if err != nil {
+			return atc.Build{}, false, err
+		}
+	}
 
-				builds = append(pageBuilds, builds...)
+	return earliest, found, nil
+}
+
+// walkBackThroughBuilds visits builds from the most recent to the oldest, a page at a time, until visit
+// returns false or there are no more builds.
+func (c checker) walkBackThroughBuilds(scope buildScope, pageSize int, visit func(atc.Build) bool) error {
+	return c.pageThroughBuilds(scope, gc.Page{Limit: pageSize}, backwards, func(builds []atc.Build) bool {
+		for _, b := range builds {
+			if !visit(b) {
+				return false
 			}
 		}
-	}
 
-	return reverseOrder(builds), nil
+		return true
+	})
 }
 
-func reverseOrder(builds []atc.Build) []atc.Build {
-	for i, j := 0, len(builds)-1; i < j; i, j = i+1, j-1 {
-		builds[i], builds[j] = builds[j], builds[i]
+// parseInitialBuildSince accepts either an RFC3339 timestamp or a duration to count back from now.
+func (c checker) parseInitialBuildSince() (time.Time, error) {
+	since := c.checkRequest.Source.InitialBuildSince
+	if since == "" {
+		return time.Time{}, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, since)
+	if err == nil {
+		return timestamp, nil
+	}
+
+	duration, err := time.ParseDuration(since)
+	if err != nil || duration <= 0 {
+		return time.Time{}, fmt.Errorf("could not parse initial_build_since '%s', expected an RFC3339 timestamp like '2018-09-01T00:00:00Z' or a duration like '72h'", since)
 	}
-	return builds
+
+	return time.Now().Add(-duration), nil
 }
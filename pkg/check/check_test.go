@@ -14,6 +14,7 @@ import (
 	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
 
 	"fmt"
+	"time"
 )
 
 func TestCheckPkg(t *testing.T) {
@@ -40,16 +41,15 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.JobBuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
+								[]atc.Build{
+									{ID: 555, Status: string(atc.StatusSucceeded)},
 								},
+								concourse.Pagination{Previous: &concourse.Page{Until: 555, Limit: 100}},
 								true,
 								nil)
-
 							faketeam.JobBuildsReturnsOnCall(1,
 								[]atc.Build{
 									{ID: 999, Status: string(atc.StatusFailed)},
-									{ID: 555, Status: string(atc.StatusSucceeded)},
 								},
 								concourse.Pagination{},
 								true,
@@ -66,13 +66,13 @@ func TestCheckPkg(t *testing.T) {
 						})
 
 						it("returns completed builds in order", func() {
-							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555"}, {BuildId: "999"}}))
+							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555", Status: "succeeded"}, {BuildId: "999", Status: "failed"}}))
 						})
 
 						it("uses pagination to get all builds since the given version", func() {
-							gt.Expect(page).To(gomega.Equal(concourse.Page{Since: 111, Limit: 100}))
+							gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 110, Limit: 100}))
 							_, _, page = faketeam.JobBuildsArgsForCall(1)
-							gt.Expect(page).To(gomega.Equal(concourse.Page{Since: 999}))
+							gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 555, Limit: 100}))
 						})
 					}, spec.Nested())
 
@@ -81,13 +81,6 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.JobBuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
-								},
-								true,
-								nil)
-
-							faketeam.JobBuildsReturnsOnCall(1,
 								[]atc.Build{
 									{ID: 999, Status: string(atc.StatusPending)},
 									{ID: 777, Status: string(atc.StatusStarted)},
@@ -106,7 +99,7 @@ func TestCheckPkg(t *testing.T) {
 						})
 
 						it("returns only the completed builds, ignoring uncompleted builds", func() {
-							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555"}}))
+							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555", Status: "succeeded"}}))
 						})
 					}, spec.Nested())
 
@@ -115,13 +108,6 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.JobBuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
-								},
-								true,
-								nil)
-
-							faketeam.JobBuildsReturnsOnCall(1,
 								[]atc.Build{
 									{ID: 777, Status: string(atc.StatusStarted)},
 									{ID: 999, Status: string(atc.StatusPending)},
@@ -149,30 +135,53 @@ func TestCheckPkg(t *testing.T) {
 
 					it.Before(func() {
 						faketeam.JobBuildsReturnsOnCall(0,
-							[]atc.Build{},
-							concourse.Pagination{Previous: &concourse.Page{Since: 999},
+							[]atc.Build{
+								{ID: 999, Status: string(atc.StatusSucceeded)},
 							},
+							concourse.Pagination{},
 							true,
 							nil)
 
-						faketeam.JobBuildsReturnsOnCall(1,
+						checker := check.NewCheckerUsingClient(&config.CheckRequest{
+							Version: config.Version{BuildId: "999"},
+							Source:  source,
+						}, fakeclient)
+						response, err = checker.Check()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("returns the version it was given", func() {
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "999"}}))
+					})
+				}, spec.Nested())
+
+				when("the version given came from a put, before its build finished", func() {
+					gt := gomega.NewGomegaWithT(t)
+					fromPut := config.Version{BuildId: "999", Team: "test-team", Pipeline: "test-pipeline", Job: "test-job", JobNumber: "12"}
+
+					it.Before(func() {
+						faketeam.JobBuildsReturnsOnCall(0,
 							[]atc.Build{
-								{ID: 999, Status: string(atc.StatusSucceeded)},
+								{ID: 1000, Name: "13", Status: string(atc.StatusFailed), TeamName: "test-team", PipelineName: "test-pipeline", JobName: "test-job"},
+								{ID: 999, Name: "12", Status: string(atc.StatusSucceeded), TeamName: "test-team", PipelineName: "test-pipeline", JobName: "test-job"},
 							},
 							concourse.Pagination{},
 							true,
 							nil)
 
 						checker := check.NewCheckerUsingClient(&config.CheckRequest{
-							Version: config.Version{BuildId: "999"},
+							Version: fromPut,
 							Source:  source,
 						}, fakeclient)
 						response, err = checker.Check()
 						gt.Expect(err).NotTo(gomega.HaveOccurred())
 					})
 
-					it("returns the version it was given", func() {
-						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{config.Version{BuildId: "999"}}))
+					it("gives that version back unchanged, so that Concourse doesn't see the build twice", func() {
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+							fromPut,
+							{BuildId: "1000", Team: "test-team", Pipeline: "test-pipeline", Job: "test-job", JobNumber: "13", Status: "failed"},
+						}))
 					})
 				}, spec.Nested())
 
@@ -211,16 +220,15 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.PipelineBuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
+								[]atc.Build{
+									{ID: 555, Status: string(atc.StatusSucceeded), JobName: "test-job"},
 								},
+								concourse.Pagination{Previous: &concourse.Page{Until: 555, Limit: 100}},
 								true,
 								nil)
-
 							faketeam.PipelineBuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 999, Status: string(atc.StatusFailed)},
-									{ID: 555, Status: string(atc.StatusSucceeded)},
+									{ID: 999, Status: string(atc.StatusFailed), JobName: "test-job"},
 								},
 								concourse.Pagination{},
 								true,
@@ -237,13 +245,16 @@ func TestCheckPkg(t *testing.T) {
 						})
 
 						it("returns completed builds in order", func() {
-							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555"}, {BuildId: "999"}}))
+							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+								{BuildId: "555", Job: "test-job", Status: "succeeded"},
+								{BuildId: "999", Job: "test-job", Status: "failed"},
+							}))
 						})
 
 						it("uses pagination to get all builds since the given version", func() {
-							gt.Expect(page).To(gomega.Equal(concourse.Page{Since: 111, Limit: 100}))
+							gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 110, Limit: 100}))
 							_, page = faketeam.PipelineBuildsArgsForCall(1)
-							gt.Expect(page).To(gomega.Equal(concourse.Page{Since: 999}))
+							gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 555, Limit: 100}))
 						})
 					}, spec.Nested())
 
@@ -252,17 +263,10 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.PipelineBuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
-								},
-								true,
-								nil)
-
-							faketeam.PipelineBuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 555, Status: string(atc.StatusSucceeded)},
-									{ID: 777, Status: string(atc.StatusStarted)},
-									{ID: 999, Status: string(atc.StatusPending)},
+									{ID: 555, Status: string(atc.StatusSucceeded), JobName: "test-job"},
+									{ID: 777, Status: string(atc.StatusStarted), JobName: "test-job"},
+									{ID: 999, Status: string(atc.StatusPending), JobName: "test-job"},
 								},
 								concourse.Pagination{},
 								true,
@@ -277,7 +281,7 @@ func TestCheckPkg(t *testing.T) {
 						})
 
 						it("returns only the completed builds, ignoring uncompleted builds", func() {
-							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555"}}))
+							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555", Job: "test-job", Status: "succeeded"}}))
 						})
 					}, spec.Nested())
 
@@ -286,16 +290,9 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.PipelineBuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
-								},
-								true,
-								nil)
-
-							faketeam.PipelineBuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 777, Status: string(atc.StatusStarted)},
-									{ID: 999, Status: string(atc.StatusPending)},
+									{ID: 777, Status: string(atc.StatusStarted), JobName: "test-job"},
+									{ID: 999, Status: string(atc.StatusPending), JobName: "test-job"},
 								},
 								concourse.Pagination{},
 								true,
@@ -320,15 +317,8 @@ func TestCheckPkg(t *testing.T) {
 
 					it.Before(func() {
 						faketeam.PipelineBuildsReturnsOnCall(0,
-							[]atc.Build{},
-							concourse.Pagination{Previous: &concourse.Page{Since: 999},
-							},
-							true,
-							nil)
-
-						faketeam.PipelineBuildsReturnsOnCall(1,
 							[]atc.Build{
-								{ID: 999, Status: string(atc.StatusSucceeded)},
+								{ID: 999, Status: string(atc.StatusSucceeded), JobName: "test-job"},
 							},
 							concourse.Pagination{},
 							true,
@@ -343,7 +333,7 @@ func TestCheckPkg(t *testing.T) {
 					})
 
 					it("returns the version it was given", func() {
-						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{config.Version{BuildId: "999"}}))
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "999"}}))
 					})
 				}, spec.Nested())
 
@@ -380,15 +370,14 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.BuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
+								[]atc.Build{
+									{ID: 555, Status: string(atc.StatusSucceeded), JobName: "test-job"},
 								},
+								concourse.Pagination{Previous: &concourse.Page{Until: 555, Limit: 100}},
 								nil)
-
 							faketeam.BuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 999, Status: string(atc.StatusFailed)},
-									{ID: 555, Status: string(atc.StatusSucceeded)},
+									{ID: 999, Status: string(atc.StatusFailed), JobName: "test-job"},
 								},
 								concourse.Pagination{},
 								nil)
@@ -404,12 +393,15 @@ func TestCheckPkg(t *testing.T) {
 						})
 
 						it("returns completed builds in order", func() {
-							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555"}, {BuildId: "999"}}))
+							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+								{BuildId: "555", Job: "test-job", Status: "succeeded"},
+								{BuildId: "999", Job: "test-job", Status: "failed"},
+							}))
 						})
 
 						it("uses pagination to get all builds since the given version", func() {
-							gt.Expect(page).To(gomega.Equal(concourse.Page{Since: 111, Limit: 100}))
-							gt.Expect(faketeam.BuildsArgsForCall(1)).To(gomega.Equal(concourse.Page{Since: 999}))
+							gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 110, Limit: 100}))
+							gt.Expect(faketeam.BuildsArgsForCall(1)).To(gomega.Equal(concourse.Page{Until: 555, Limit: 100}))
 						})
 					}, spec.Nested())
 
@@ -418,16 +410,10 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.BuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
-								},
-								nil)
-
-							faketeam.BuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 555, Status: string(atc.StatusSucceeded)},
-									{ID: 777, Status: string(atc.StatusStarted)},
-									{ID: 999, Status: string(atc.StatusPending)},
+									{ID: 555, Status: string(atc.StatusSucceeded), JobName: "test-job"},
+									{ID: 777, Status: string(atc.StatusStarted), JobName: "test-job"},
+									{ID: 999, Status: string(atc.StatusPending), JobName: "test-job"},
 								},
 								concourse.Pagination{},
 								nil)
@@ -441,7 +427,7 @@ func TestCheckPkg(t *testing.T) {
 						})
 
 						it("returns only the completed builds, ignoring uncompleted builds", func() {
-							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555"}}))
+							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555", Job: "test-job", Status: "succeeded"}}))
 						})
 					}, spec.Nested())
 
@@ -450,15 +436,9 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							faketeam.BuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
-								},
-								nil)
-
-							faketeam.BuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 777, Status: string(atc.StatusStarted)},
-									{ID: 999, Status: string(atc.StatusPending)},
+									{ID: 777, Status: string(atc.StatusStarted), JobName: "test-job"},
+									{ID: 999, Status: string(atc.StatusPending), JobName: "test-job"},
 								},
 								concourse.Pagination{},
 								nil)
@@ -482,14 +462,8 @@ func TestCheckPkg(t *testing.T) {
 
 					it.Before(func() {
 						faketeam.BuildsReturnsOnCall(0,
-							[]atc.Build{},
-							concourse.Pagination{Previous: &concourse.Page{Since: 999},
-							},
-							nil)
-
-						faketeam.BuildsReturnsOnCall(1,
 							[]atc.Build{
-								{ID: 999, Status: string(atc.StatusSucceeded)},
+								{ID: 999, Status: string(atc.StatusSucceeded), JobName: "test-job"},
 							},
 							concourse.Pagination{},
 							nil)
@@ -503,7 +477,7 @@ func TestCheckPkg(t *testing.T) {
 					})
 
 					it("returns the version it was given", func() {
-						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{config.Version{BuildId: "999"}}))
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "999"}}))
 					})
 				}, spec.Nested())
 
@@ -539,14 +513,13 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							fakeclient.BuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999}},
+								[]atc.Build{
+									{ID: 555, Status: string(atc.StatusSucceeded), JobName: "test-job"}},
+								concourse.Pagination{Previous: &concourse.Page{Until: 555, Limit: 100}},
 								nil)
-
 							fakeclient.BuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 999, Status: string(atc.StatusFailed)},
-									{ID: 555, Status: string(atc.StatusSucceeded)}},
+									{ID: 999, Status: string(atc.StatusFailed), JobName: "test-job"}},
 								concourse.Pagination{},
 								nil)
 
@@ -561,12 +534,15 @@ func TestCheckPkg(t *testing.T) {
 						})
 
 						it("returns completed builds in order", func() {
-							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555"}, {BuildId: "999"}}))
+							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+								{BuildId: "555", Job: "test-job", Status: "succeeded"},
+								{BuildId: "999", Job: "test-job", Status: "failed"},
+							}))
 						})
 
 						it("uses pagination to get all builds since the given version", func() {
-							gt.Expect(page).To(gomega.Equal(concourse.Page{Since: 111, Limit: 100}))
-							gt.Expect(fakeclient.BuildsArgsForCall(1)).To(gomega.Equal(concourse.Page{Since: 999}))
+							gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 110, Limit: 100}))
+							gt.Expect(fakeclient.BuildsArgsForCall(1)).To(gomega.Equal(concourse.Page{Until: 555, Limit: 100}))
 						})
 					}, spec.Nested())
 
@@ -575,16 +551,10 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							fakeclient.BuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
-								},
-								nil)
-
-							fakeclient.BuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 555, Status: string(atc.StatusSucceeded)},
-									{ID: 777, Status: string(atc.StatusStarted)},
-									{ID: 999, Status: string(atc.StatusPending)},
+									{ID: 555, Status: string(atc.StatusSucceeded), JobName: "test-job"},
+									{ID: 777, Status: string(atc.StatusStarted), JobName: "test-job"},
+									{ID: 999, Status: string(atc.StatusPending), JobName: "test-job"},
 								},
 								concourse.Pagination{},
 								nil)
@@ -598,7 +568,7 @@ func TestCheckPkg(t *testing.T) {
 						})
 
 						it("returns only the completed builds, ignoring uncompleted builds", func() {
-							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555"}}))
+							gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555", Job: "test-job", Status: "succeeded"}}))
 						})
 					}, spec.Nested())
 
@@ -607,15 +577,9 @@ func TestCheckPkg(t *testing.T) {
 
 						it.Before(func() {
 							fakeclient.BuildsReturnsOnCall(0,
-								[]atc.Build{},
-								concourse.Pagination{Previous: &concourse.Page{Since: 999},
-								},
-								nil)
-
-							fakeclient.BuildsReturnsOnCall(1,
 								[]atc.Build{
-									{ID: 777, Status: string(atc.StatusStarted)},
-									{ID: 999, Status: string(atc.StatusPending)},
+									{ID: 777, Status: string(atc.StatusStarted), JobName: "test-job"},
+									{ID: 999, Status: string(atc.StatusPending), JobName: "test-job"},
 								},
 								concourse.Pagination{},
 								nil)
@@ -639,13 +603,8 @@ func TestCheckPkg(t *testing.T) {
 
 					it.Before(func() {
 						fakeclient.BuildsReturnsOnCall(0,
-							[]atc.Build{},
-							concourse.Pagination{Previous: &concourse.Page{Since: 999}},
-							nil)
-
-						fakeclient.BuildsReturnsOnCall(1,
 							[]atc.Build{
-								{ID: 999, Status: string(atc.StatusSucceeded)}},
+								{ID: 999, Status: string(atc.StatusSucceeded), JobName: "test-job"}},
 							concourse.Pagination{},
 							nil)
 
@@ -658,7 +617,7 @@ func TestCheckPkg(t *testing.T) {
 					})
 
 					it("returns the version it was given", func() {
-						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{config.Version{BuildId: "999"}}))
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "999"}}))
 					})
 				}, spec.Nested())
 
@@ -731,6 +690,41 @@ func TestCheckPkg(t *testing.T) {
 				})
 			}, spec.Nested())
 
+			when("the latest build has not finished", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{{ID: 222, Status: string(atc.StatusStarted)}},
+						concourse.Pagination{},
+						true,
+						nil)
+					faketeam.JobBuildsReturnsOnCall(1,
+						[]atc.Build{{ID: 222, Status: string(atc.StatusStarted)}, {ID: 111, Status: string(atc.StatusSucceeded)}},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{Source: config.Source{
+						ConcourseUrl: "https://example.com",
+						Team:         "test-team",
+						Pipeline:     "test-pipeline",
+						Job:          "test-job",
+					}}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns the latest finished build instead", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "111", Status: "succeeded"}}))
+				})
+			}, spec.Nested())
+
 			when("initial_build_id has been set", func() {
 				gt := gomega.NewGomegaWithT(t)
 				faketeam := new(fakes.FakeTeam)
@@ -744,19 +738,1337 @@ func TestCheckPkg(t *testing.T) {
 				var err error
 
 				it.Before(func() {
+					fakeclient.BuildReturns(atc.Build{ID: 222, Status: string(atc.StatusSucceeded), PipelineName: "test-pipeline", JobName: "test-job", Name: "7"}, true, nil)
+
 					checker := check.NewCheckerUsingClient(&config.CheckRequest{Source: source}, fakeclient)
 					response, err = checker.Check()
 					gt.Expect(err).NotTo(gomega.HaveOccurred())
 				})
 
-				it("returns that initial_build_id as the first version", func() {
-					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "222"}}))
+				it("returns that initial_build_id as the first version, described like any other", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "222", Pipeline: "test-pipeline", Job: "test-job", JobNumber: "7", Status: "succeeded"}}))
+					gt.Expect(fakeclient.BuildArgsForCall(0)).To(gomega.Equal("222"))
 				})
 
-				it("does not bother dialling out to the remote Concourse", func() {
+				it("does not bother listing builds on the remote Concourse", func() {
 					gt.Expect(fakeclient.BuildsCallCount()).To(gomega.BeZero())
 				})
 			})
+
+			when("initial_build_id cannot be found", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.BuildReturns(atc.Build{}, false, nil)
+
+				it("returns an error", func() {
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{Source: config.Source{
+						ConcourseUrl:   "https://example.com",
+						InitialBuildId: 222,
+					}}, fakeclient)
+					_, err := checker.Check()
+					gt.Expect(err).To(gomega.MatchError("server could not find initial_build_id '222'"))
+				})
+			})
+
+			when("initial_build_since has been set", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+				now := time.Now()
+				hoursAgo := func(hours int) int64 {
+					return now.Add(time.Duration(-hours) * time.Hour).Unix()
+				}
+
+				it.Before(func() {
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusStarted), StartTime: hoursAgo(1)},
+							{ID: 888, Status: string(atc.StatusSucceeded), StartTime: hoursAgo(24)},
+						},
+						concourse.Pagination{Next: &concourse.Page{Until: 888, Limit: 2}},
+						true,
+						nil)
+
+					faketeam.JobBuildsReturnsOnCall(1,
+						[]atc.Build{
+							{ID: 777, Status: string(atc.StatusFailed), StartTime: hoursAgo(48)},
+							{ID: 666, Status: string(atc.StatusSucceeded), StartTime: hoursAgo(96)},
+						},
+						concourse.Pagination{Next: &concourse.Page{Until: 666, Limit: 2}},
+						true,
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Source: config.Source{
+							ConcourseUrl:      "https://example.com",
+							Team:              "test-team",
+							Pipeline:          "test-pipeline",
+							Job:               "test-job",
+							FetchPageSize:     2,
+							InitialBuildSince: "72h",
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns the earliest finished build since then as the first version", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "777", Status: "failed"}}))
+				})
+
+				it("stops paging back once it passes that time", func() {
+					gt.Expect(faketeam.JobBuildsCallCount()).To(gomega.Equal(2))
+					_, _, page := faketeam.JobBuildsArgsForCall(1)
+					gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 888, Limit: 2}))
+				})
+			}, spec.Nested())
+
+			when("initial_build_since has been set and the latest build has not started yet", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+				minutesAgo := func(minutes int) int64 {
+					return time.Now().Add(time.Duration(-minutes) * time.Minute).Unix()
+				}
+
+				it.Before(func() {
+					faketeam.JobBuildsReturns(
+						[]atc.Build{
+							{ID: 10, Status: string(atc.StatusPending)},
+							{ID: 9, Status: string(atc.StatusSucceeded), StartTime: minutesAgo(10)},
+							{ID: 8, Status: string(atc.StatusSucceeded), StartTime: minutesAgo(20)},
+							{ID: 7, Status: string(atc.StatusSucceeded), StartTime: minutesAgo(120)},
+						},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Source: config.Source{
+							ConcourseUrl:      "https://example.com",
+							Team:              "test-team",
+							Pipeline:          "test-pipeline",
+							Job:               "test-job",
+							InitialBuildSince: "1h",
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("looks past it to the builds which started since then", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "8", Status: "succeeded"}}))
+				})
+			}, spec.Nested())
+
+			when("initial_build_since is a timestamp", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.BuildsReturns(
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusSucceeded), JobName: "test-job", StartTime: 1536000000},
+							{ID: 888, Status: string(atc.StatusSucceeded), JobName: "test-job", StartTime: 1535000000},
+						},
+						concourse.Pagination{},
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Source: config.Source{
+							ConcourseUrl:      "https://example.com",
+							Team:              "test-team",
+							InitialBuildSince: "2018-09-01T00:00:00Z",
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns the earliest build which started after it", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "999", Job: "test-job", Status: "succeeded"}}))
+				})
+			}, spec.Nested())
+
+			when("initial_build_since cannot be parsed", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(new(fakes.FakeTeam))
+				var err error
+
+				it.Before(func() {
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Source: config.Source{ConcourseUrl: "https://example.com", InitialBuildSince: "last tuesday"},
+					}, fakeclient)
+					_, err = checker.Check()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err.Error()).To(gomega.ContainSubstring("could not parse initial_build_since 'last tuesday'"))
+				})
+			}, spec.Nested())
+
+			when("both initial_build_id and initial_build_since are set", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(new(fakes.FakeTeam))
+				var err error
+
+				it.Before(func() {
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Source: config.Source{ConcourseUrl: "https://example.com", InitialBuildId: 222, InitialBuildSince: "72h"},
+					}, fakeclient)
+					_, err = checker.Check()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err).To(gomega.MatchError("only one of initial_build_id or initial_build_since can be set"))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("statuses are given", func() {
+			source := config.Source{
+				ConcourseUrl: "https://example.com",
+				Team:         "test-team",
+				Pipeline:     "test-pipeline",
+				Job:          "test-job",
+				Statuses:     []string{"failed", "errored"},
+			}
+
+			when("there are new builds with a mix of statuses", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusSucceeded)},
+							{ID: 888, Status: string(atc.StatusErrored)},
+							{ID: 777, Status: string(atc.StatusStarted)},
+							{ID: 666, Status: string(atc.StatusAborted)},
+							{ID: 555, Status: string(atc.StatusFailed)},
+						},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  source,
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns only the builds with those statuses, in order", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "555", Status: "failed"}, {BuildId: "888", Status: "errored"}}))
+				})
+			}, spec.Nested())
+
+			when("there are new builds, but none with those statuses", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{{ID: 999, Status: string(atc.StatusSucceeded)}},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  source,
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns the version given", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "111"}}))
+				})
+			}, spec.Nested())
+
+			when("there are more new builds than fit on a page", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 333, Status: string(atc.StatusFailed)},
+							{ID: 222, Status: string(atc.StatusSucceeded)},
+						},
+						concourse.Pagination{Previous: &concourse.Page{Until: 333, Limit: 2}},
+						true,
+						nil)
+					faketeam.JobBuildsReturnsOnCall(1,
+						[]atc.Build{
+							{ID: 555, Status: string(atc.StatusSucceeded)},
+							{ID: 444, Status: string(atc.StatusErrored)},
+						},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					pagedSource := source
+					pagedSource.FetchPageSize = 2
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  pagedSource,
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("pages forwards through every build since the version given", func() {
+					gt.Expect(faketeam.JobBuildsCallCount()).To(gomega.Equal(2))
+					_, _, page := faketeam.JobBuildsArgsForCall(0)
+					gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 110, Limit: 2}))
+					_, _, page = faketeam.JobBuildsArgsForCall(1)
+					gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 333, Limit: 2}))
+				})
+
+				it("returns the builds with those statuses from every page", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "333", Status: "failed"}, {BuildId: "444", Status: "errored"}}))
+				})
+			}, spec.Nested())
+
+			when("this is the first check", func() {
+				when("the latest wanted build is not on the first page", func() {
+					gt := gomega.NewGomegaWithT(t)
+					faketeam := new(fakes.FakeTeam)
+					fakeclient := new(fakes.FakeClient)
+					fakeclient.TeamReturns(faketeam)
+					var response *config.CheckResponse
+
+					it.Before(func() {
+						faketeam.JobBuildsReturnsOnCall(0,
+							[]atc.Build{
+								{ID: 999, Status: string(atc.StatusSucceeded)},
+								{ID: 888, Status: string(atc.StatusStarted)},
+							},
+							concourse.Pagination{Next: &concourse.Page{Until: 888, Limit: 100}},
+							true,
+							nil)
+
+						faketeam.JobBuildsReturnsOnCall(1,
+							[]atc.Build{
+								{ID: 777, Status: string(atc.StatusFailed)},
+								{ID: 666, Status: string(atc.StatusErrored)},
+							},
+							concourse.Pagination{},
+							true,
+							nil)
+
+						checker := check.NewCheckerUsingClient(&config.CheckRequest{Source: source}, fakeclient)
+						var err error
+						response, err = checker.Check()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("pages back to find the most recent build with one of those statuses", func() {
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "777", Status: "failed"}}))
+
+						_, _, page := faketeam.JobBuildsArgsForCall(0)
+						gt.Expect(page).To(gomega.Equal(concourse.Page{Limit: 100}))
+						_, _, page = faketeam.JobBuildsArgsForCall(1)
+						gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 888, Limit: 100}))
+					})
+				}, spec.Nested())
+
+				when("no build has those statuses", func() {
+					gt := gomega.NewGomegaWithT(t)
+					faketeam := new(fakes.FakeTeam)
+					fakeclient := new(fakes.FakeClient)
+					fakeclient.TeamReturns(faketeam)
+					var response *config.CheckResponse
+
+					it.Before(func() {
+						faketeam.JobBuildsReturns(
+							[]atc.Build{{ID: 999, Status: string(atc.StatusSucceeded)}},
+							concourse.Pagination{},
+							true,
+							nil)
+
+						checker := check.NewCheckerUsingClient(&config.CheckRequest{Source: source}, fakeclient)
+						var err error
+						response, err = checker.Check()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("returns an empty version array", func() {
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{}))
+					})
+				}, spec.Nested())
+				when("no recent build has those statuses", func() {
+					gt := gomega.NewGomegaWithT(t)
+					faketeam := new(fakes.FakeTeam)
+					fakeclient := new(fakes.FakeClient)
+					fakeclient.TeamReturns(faketeam)
+					var response *config.CheckResponse
+
+					it.Before(func() {
+						faketeam.JobBuildsReturns(
+							[]atc.Build{
+								{ID: 999, Status: string(atc.StatusSucceeded)},
+								{ID: 888, Status: string(atc.StatusSucceeded)},
+							},
+							concourse.Pagination{Next: &concourse.Page{Since: 888, Limit: 2}},
+							true,
+							nil)
+
+						pagedSource := source
+						pagedSource.FetchPageSize = 2
+						checker := check.NewCheckerUsingClient(&config.CheckRequest{Source: pagedSource}, fakeclient)
+						var err error
+						response, err = checker.Check()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("gives up after 10 pages", func() {
+						gt.Expect(faketeam.JobBuildsCallCount()).To(gomega.Equal(10))
+					})
+
+					it("returns an empty version array", func() {
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{}))
+					})
+				}, spec.Nested())
+			}, spec.Nested())
+
+			when("an unknown status is given", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				var response *config.CheckResponse
+				var err error
+
+				it.Before(func() {
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  config.Source{ConcourseUrl: "https://example.com", Statuses: []string{"failed", "flaky"}},
+					}, fakeclient)
+					response, err = checker.Check()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(response).To(gomega.BeNil())
+					gt.Expect(err.Error()).To(gomega.ContainSubstring("unknown status 'flaky' in statuses"))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("pipelines or jobs are given as lists", func() {
+			when("jobs are selected by pattern across pipelines selected by pattern", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.ListPipelinesReturns([]atc.Pipeline{{Name: "deploy-staging"}, {Name: "deploy-prod"}, {Name: "unrelated"}}, nil)
+					faketeam.ListJobsReturnsOnCall(0, []atc.Job{{Name: "deploy-web"}, {Name: "unit-tests"}}, nil)
+					faketeam.ListJobsReturnsOnCall(1, []atc.Job{{Name: "deploy-api"}, {Name: "smoke-tests"}}, nil)
+
+					faketeam.JobBuildsStub = func(pipeline string, job string, page concourse.Page) ([]atc.Build, concourse.Pagination, bool, error) {
+						switch pipeline + "/" + job {
+						case "deploy-staging/deploy-web":
+							return []atc.Build{{ID: 700, Status: string(atc.StatusSucceeded)}, {ID: 300, Status: string(atc.StatusSucceeded)}}, concourse.Pagination{}, true, nil
+						case "deploy-prod/deploy-api":
+							return []atc.Build{{ID: 500, Status: string(atc.StatusFailed)}}, concourse.Pagination{}, true, nil
+						default:
+							return nil, concourse.Pagination{}, false, nil
+						}
+					}
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "test-team",
+							Pipelines:    []string{"deploy-*"},
+							Jobs:         []string{"/^deploy-/"},
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("only looks at jobs in matching pipelines", func() {
+					gt.Expect(faketeam.ListJobsCallCount()).To(gomega.Equal(2))
+					gt.Expect(faketeam.ListJobsArgsForCall(0)).To(gomega.Equal("deploy-staging"))
+					gt.Expect(faketeam.ListJobsArgsForCall(1)).To(gomega.Equal("deploy-prod"))
+				})
+
+				it("merges the builds of matching jobs, ordered by global build ID", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "300", Status: "succeeded"},
+						{BuildId: "500", Status: "failed"},
+						{BuildId: "700", Status: "succeeded"},
+					}))
+				})
+			}, spec.Nested())
+
+			when("one of the jobs has no builds before the given version", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.ListPipelinesReturns([]atc.Pipeline{{Name: "test-pipeline"}}, nil)
+					faketeam.ListJobsReturns([]atc.Job{{Name: "web"}, {Name: "api"}}, nil)
+
+					faketeam.JobBuildsStub = func(pipeline string, job string, page concourse.Page) ([]atc.Build, concourse.Pagination, bool, error) {
+						switch {
+						case job == "web" && page.Until == 499:
+							return []atc.Build{{ID: 600, Status: string(atc.StatusSucceeded)}, {ID: 500, Status: string(atc.StatusSucceeded)}},
+								concourse.Pagination{Previous: &concourse.Page{Until: 600, Limit: 100}}, true, nil
+						case job == "web" && page.Until == 600:
+							return []atc.Build{{ID: 800, Status: string(atc.StatusSucceeded)}}, concourse.Pagination{}, true, nil
+						case job == "api" && page.Until == 499:
+							return []atc.Build{{ID: 700, Status: string(atc.StatusFailed)}}, concourse.Pagination{}, true, nil
+						default:
+							return nil, concourse.Pagination{}, false, nil
+						}
+					}
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "500"},
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "test-team",
+							Pipeline:     "test-pipeline",
+							Jobs:         []string{"web", "api"},
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("pages each job forwards from the given version", func() {
+					gt.Expect(faketeam.JobBuildsCallCount()).To(gomega.Equal(3))
+				})
+
+				it("returns the builds of both jobs, ordered by global build ID", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "500"},
+						{BuildId: "600", Status: "succeeded"},
+						{BuildId: "700", Status: "failed"},
+						{BuildId: "800", Status: "succeeded"},
+					}))
+				})
+			}, spec.Nested())
+
+			when("this is the first check", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.ListPipelinesReturns([]atc.Pipeline{{Name: "pipeline-a"}, {Name: "pipeline-b"}}, nil)
+					faketeam.PipelineBuildsStub = func(pipeline string, page concourse.Page) ([]atc.Build, concourse.Pagination, bool, error) {
+						if pipeline == "pipeline-a" {
+							return []atc.Build{{ID: 800, Status: string(atc.StatusSucceeded), JobName: "job-a"}}, concourse.Pagination{}, true, nil
+						}
+						return []atc.Build{{ID: 900, Status: string(atc.StatusSucceeded), JobName: "job-b"}}, concourse.Pagination{}, true, nil
+					}
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "test-team",
+							Pipelines:    []string{"pipeline-a", "pipeline-b"},
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns the most recent build across all the pipelines", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "900", Job: "job-b", Status: "succeeded"}}))
+				})
+			}, spec.Nested())
+
+			when("nothing matches", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var err error
+
+				it.Before(func() {
+					faketeam.ListPipelinesReturns([]atc.Pipeline{{Name: "unrelated"}}, nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  config.Source{ConcourseUrl: "https://example.com", Team: "test-team", Pipelines: []string{"deploy-*"}},
+					}, fakeclient)
+					_, err = checker.Check()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err.Error()).To(gomega.ContainSubstring("no pipelines or jobs in team 'test-team' matched"))
+				})
+			}, spec.Nested())
+
+			when("team is not given", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(new(fakes.FakeTeam))
+				var err error
+
+				it.Before(func() {
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  config.Source{ConcourseUrl: "https://example.com", Jobs: []string{"deploy-*"}},
+					}, fakeclient)
+					_, err = checker.Check()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err.Error()).To(gomega.ContainSubstring("team must be set when using pipelines or jobs"))
+				})
+			}, spec.Nested())
+
+			when("a regular expression is malformed", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(new(fakes.FakeTeam))
+				var err error
+
+				it.Before(func() {
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  config.Source{ConcourseUrl: "https://example.com", Team: "test-team", Jobs: []string{"/deploy-(/"}},
+					}, fakeclient)
+					_, err = checker.Check()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err.Error()).To(gomega.ContainSubstring("could not parse regular expression '/deploy-(/' in jobs"))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("exclusions are given", func() {
+			when("checking all jobs in a team", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.BuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusSucceeded), PipelineName: "main", JobName: "deploy"},
+							{ID: 888, Status: string(atc.StatusSucceeded), PipelineName: "main", JobName: "cleanup-workers"},
+							{ID: 777, Status: string(atc.StatusSucceeded), PipelineName: "housekeeping", JobName: "deploy"},
+							{ID: 666, Status: string(atc.StatusFailed), PipelineName: "main", JobName: "unit"},
+						},
+						concourse.Pagination{},
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source: config.Source{
+							ConcourseUrl:     "https://example.com",
+							Team:             "test-team",
+							ExcludePipelines: []string{"housekeeping"},
+							ExcludeJobs:      []string{"cleanup-*"},
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("drops builds from excluded pipelines and jobs", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "666", Pipeline: "main", Job: "unit", Status: "failed"},
+						{BuildId: "999", Pipeline: "main", Job: "deploy", Status: "succeeded"},
+					}))
+				})
+			}, spec.Nested())
+
+			when("the builds since the version span several pages", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.BuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 333, Status: string(atc.StatusSucceeded), PipelineName: "main", JobName: "unit"},
+							{ID: 222, Status: string(atc.StatusSucceeded), PipelineName: "main", JobName: "cleanup-workers"},
+						},
+						concourse.Pagination{Previous: &concourse.Page{Until: 333, Limit: 2}},
+						nil)
+					faketeam.BuildsReturnsOnCall(1,
+						[]atc.Build{
+							{ID: 555, Status: string(atc.StatusSucceeded), PipelineName: "main", JobName: "cleanup-workers"},
+							{ID: 444, Status: string(atc.StatusFailed), PipelineName: "main", JobName: "deploy"},
+						},
+						concourse.Pagination{},
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source: config.Source{
+							ConcourseUrl:  "https://example.com",
+							Team:          "test-team",
+							ExcludeJobs:   []string{"cleanup-*"},
+							FetchPageSize: 2,
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("keeps every build which is not excluded, from every page", func() {
+					gt.Expect(faketeam.BuildsCallCount()).To(gomega.Equal(2))
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "333", Pipeline: "main", Job: "unit", Status: "succeeded"},
+						{BuildId: "444", Pipeline: "main", Job: "deploy", Status: "failed"},
+					}))
+				})
+			}, spec.Nested())
+
+			when("checking all jobs in all teams", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(new(fakes.FakeTeam))
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					fakeclient.BuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusSucceeded), TeamName: "sandbox-alice", JobName: "test-job"},
+							{ID: 888, Status: string(atc.StatusSucceeded), TeamName: "main", JobName: "test-job"},
+						},
+						concourse.Pagination{},
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							ExcludeTeams: []string{"/^sandbox-/"},
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("drops builds from excluded teams", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "888", Team: "main", Job: "test-job", Status: "succeeded"}}))
+				})
+			}, spec.Nested())
+
+			when("this is the first check and the latest build is excluded", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.BuildsReturns(
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusSucceeded), JobName: "cleanup-workers"},
+							{ID: 888, Status: string(atc.StatusSucceeded), JobName: "deploy"},
+						},
+						concourse.Pagination{},
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "test-team",
+							ExcludeJobs:  []string{"cleanup-*"},
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns the most recent build which is not excluded", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "888", Job: "deploy", Status: "succeeded"}}))
+				})
+			}, spec.Nested())
+
+			when("selecting jobs from a list of pipelines", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+
+				it.Before(func() {
+					faketeam.ListPipelinesReturns([]atc.Pipeline{{Name: "deploy-prod"}, {Name: "deploy-old"}}, nil)
+					faketeam.PipelineBuildsReturns([]atc.Build{}, concourse.Pagination{}, true, nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source: config.Source{
+							ConcourseUrl:     "https://example.com",
+							Team:             "test-team",
+							Pipelines:        []string{"deploy-*"},
+							ExcludePipelines: []string{"deploy-old"},
+						},
+					}, fakeclient)
+					_, err := checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("does not fetch builds for excluded pipelines at all", func() {
+					gt.Expect(faketeam.PipelineBuildsCallCount()).To(gomega.Equal(1))
+					pipeline, _ := faketeam.PipelineBuildsArgsForCall(0)
+					gt.Expect(pipeline).To(gomega.Equal("deploy-prod"))
+				})
+			}, spec.Nested())
+
+			when("a pattern is malformed", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(new(fakes.FakeTeam))
+				var err error
+
+				it.Before(func() {
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  config.Source{ConcourseUrl: "https://example.com", ExcludeTeams: []string{"[sandbox"}},
+					}, fakeclient)
+					_, err = checker.Check()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err.Error()).To(gomega.ContainSubstring("could not parse pattern '[sandbox' in exclude_teams"))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("one_off_builds is given", func() {
+			gt := gomega.NewGomegaWithT(t)
+
+			oneOffBuilds := func(setting string) *config.CheckResponse {
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+
+				faketeam.BuildsReturnsOnCall(0,
+					[]atc.Build{
+						{ID: 999, Status: string(atc.StatusSucceeded)},
+						{ID: 888, Status: string(atc.StatusSucceeded), PipelineName: "pipeline", JobName: "job"},
+					},
+					concourse.Pagination{},
+					nil)
+
+				checker := check.NewCheckerUsingClient(&config.CheckRequest{
+					Version: config.Version{BuildId: "111"},
+					Source: config.Source{
+						ConcourseUrl: "https://example.com",
+						Team:         "test-team",
+						OneOffBuilds: setting,
+					},
+				}, fakeclient)
+				response, err := checker.Check()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				return response
+			}
+
+			it("leaves out one-off builds by default", func() {
+				gt.Expect(oneOffBuilds("")).To(gomega.Equal(&config.CheckResponse{{BuildId: "888", Pipeline: "pipeline", Job: "job", Status: "succeeded"}}))
+			})
+
+			it("leaves out one-off builds when set to 'exclude'", func() {
+				gt.Expect(oneOffBuilds("exclude")).To(gomega.Equal(&config.CheckResponse{{BuildId: "888", Pipeline: "pipeline", Job: "job", Status: "succeeded"}}))
+			})
+
+			it("returns one-off builds alongside job builds when set to 'include'", func() {
+				gt.Expect(oneOffBuilds("include")).To(gomega.Equal(&config.CheckResponse{
+					{BuildId: "888", Pipeline: "pipeline", Job: "job", Status: "succeeded"},
+					{BuildId: "999", Status: "succeeded"},
+				}))
+			})
+
+			it("returns only one-off builds when set to 'only'", func() {
+				gt.Expect(oneOffBuilds("only")).To(gomega.Equal(&config.CheckResponse{{BuildId: "999", Status: "succeeded"}}))
+			})
+
+			when("there is no version yet", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				checker := check.NewCheckerUsingClient(&config.CheckRequest{
+					Source: config.Source{ConcourseUrl: "https://example.com", Team: "test-team"},
+				}, fakeclient)
+
+				when("the latest build is a job build", func() {
+					var response *config.CheckResponse
+
+					it.Before(func() {
+						faketeam.BuildsReturns(
+							[]atc.Build{{ID: 999, Status: string(atc.StatusSucceeded), PipelineName: "pipeline", JobName: "job"}},
+							concourse.Pagination{},
+							nil)
+
+						var err error
+						response, err = checker.Check()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("returns it without looking further back", func() {
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "999", Pipeline: "pipeline", Job: "job", Status: "succeeded"}}))
+						gt.Expect(faketeam.BuildsCallCount()).To(gomega.Equal(1))
+						gt.Expect(faketeam.BuildsArgsForCall(0)).To(gomega.Equal(concourse.Page{Limit: 1}))
+					})
+				}, spec.Nested())
+
+				when("the latest build is a one-off build", func() {
+					var response *config.CheckResponse
+
+					it.Before(func() {
+						faketeam.BuildsReturnsOnCall(0,
+							[]atc.Build{{ID: 999, Status: string(atc.StatusSucceeded)}},
+							concourse.Pagination{},
+							nil)
+
+						faketeam.BuildsReturnsOnCall(1,
+							[]atc.Build{
+								{ID: 999, Status: string(atc.StatusSucceeded)},
+								{ID: 888, Status: string(atc.StatusSucceeded), PipelineName: "pipeline", JobName: "job"},
+							},
+							concourse.Pagination{},
+							nil)
+
+						var err error
+						response, err = checker.Check()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("looks back for the latest job build", func() {
+						gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "888", Pipeline: "pipeline", Job: "job", Status: "succeeded"}}))
+					})
+				}, spec.Nested())
+			}, spec.Nested())
+
+			when("an unknown setting is given", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(new(fakes.FakeTeam))
+				var err error
+
+				it.Before(func() {
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111"},
+						Source:  config.Source{ConcourseUrl: "https://example.com", OneOffBuilds: "sometimes"},
+					}, fakeclient)
+					_, err = checker.Check()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err).To(gomega.MatchError("unknown one_off_builds setting 'sometimes', expected 'include', 'exclude' or 'only'"))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("builds have details to show in the Concourse UI", func() {
+			gt := gomega.NewGomegaWithT(t)
+			faketeam := new(fakes.FakeTeam)
+			fakeclient := new(fakes.FakeClient)
+			fakeclient.TeamReturns(faketeam)
+			var response *config.CheckResponse
+
+			it.Before(func() {
+				faketeam.JobBuildsReturns(
+					[]atc.Build{{
+						ID:           999,
+						Name:         "42",
+						TeamName:     "test-team",
+						PipelineName: "test-pipeline",
+						JobName:      "test-job",
+						Status:       string(atc.StatusSucceeded),
+					}},
+					concourse.Pagination{},
+					true,
+					nil)
+
+				checker := check.NewCheckerUsingClient(&config.CheckRequest{
+					Source: config.Source{
+						ConcourseUrl: "https://example.com",
+						Team:         "test-team",
+						Pipeline:     "test-pipeline",
+						Job:          "test-job",
+					},
+				}, fakeclient)
+				var err error
+				response, err = checker.Check()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("adds them to the version", func() {
+				gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{
+					BuildId:   "999",
+					Team:      "test-team",
+					Pipeline:  "test-pipeline",
+					Job:       "test-job",
+					JobNumber: "42",
+					Status:    "succeeded",
+				}}))
+			})
+		}, spec.Nested())
+
+		when("include_running is set", func() {
+			when("there are started and finished builds", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusPending)},
+							{ID: 888, Status: string(atc.StatusStarted)},
+							{ID: 777, Status: string(atc.StatusFailed)},
+						},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "111", Phase: "finished"},
+						Source: config.Source{
+							ConcourseUrl:   "https://example.com",
+							Team:           "test-team",
+							Pipeline:       "test-pipeline",
+							Job:            "test-job",
+							Statuses:       []string{"succeeded"},
+							IncludeRunning: true,
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns started builds whatever the statuses, marked with their phase", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "888", Phase: "started"}}))
+				})
+			}, spec.Nested())
+
+			when("the previous version was a started build", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusStarted)},
+							{ID: 888, Status: string(atc.StatusSucceeded)},
+						},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "888", Phase: "started"},
+						Source: config.Source{
+							ConcourseUrl:   "https://example.com",
+							Team:           "test-team",
+							Pipeline:       "test-pipeline",
+							Job:            "test-job",
+							IncludeRunning: true,
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("looks at that build again", func() {
+					_, _, page := faketeam.JobBuildsArgsForCall(0)
+					gt.Expect(page.Until).To(gomega.Equal(887))
+				})
+
+				it("returns it again once it has finished", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "888", Phase: "finished", Status: "succeeded"},
+						{BuildId: "999", Phase: "started"},
+					}))
+				})
+			}, spec.Nested())
+
+			when("a later build finishes before an earlier one", func() {
+				gt := gomega.NewGomegaWithT(t)
+				source := config.Source{
+					ConcourseUrl:   "https://example.com",
+					Team:           "test-team",
+					Pipeline:       "test-pipeline",
+					Job:            "test-job",
+					IncludeRunning: true,
+				}
+
+				checkWith := func(version config.Version, builds []atc.Build) *config.CheckResponse {
+					faketeam := new(fakes.FakeTeam)
+					fakeclient := new(fakes.FakeClient)
+					fakeclient.TeamReturns(faketeam)
+
+					faketeam.JobBuildsReturnsOnCall(0, builds, concourse.Pagination{}, true, nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{Version: version, Source: source}, fakeclient)
+					response, err := checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					return response
+				}
+
+				it("holds back the later build until the earlier one has finished", func() {
+					started := config.Version{BuildId: "888", Phase: "started"}
+					response := checkWith(started, []atc.Build{
+						{ID: 890, Status: string(atc.StatusStarted)},
+						{ID: 889, Status: string(atc.StatusSucceeded)},
+						{ID: 888, Status: string(atc.StatusStarted)},
+					})
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{started}))
+
+					response = checkWith(started, []atc.Build{
+						{ID: 890, Status: string(atc.StatusStarted)},
+						{ID: 889, Status: string(atc.StatusSucceeded)},
+						{ID: 888, Status: string(atc.StatusFailed)},
+					})
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "888", Phase: "finished", Status: "failed"},
+						{BuildId: "889", Phase: "finished", Status: "succeeded"},
+						{BuildId: "890", Phase: "started"},
+					}))
+				})
+
+				it("holds back the later build while the earlier one is still pending", func() {
+					finished := config.Version{BuildId: "887", Phase: "finished", Status: "succeeded"}
+					response := checkWith(finished, []atc.Build{
+						{ID: 890, Status: string(atc.StatusSucceeded)},
+						{ID: 889, Status: string(atc.StatusPending)},
+						{ID: 888, Status: string(atc.StatusSucceeded)},
+					})
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "888", Phase: "finished", Status: "succeeded"},
+					}))
+
+					response = checkWith(config.Version{BuildId: "888", Phase: "finished", Status: "succeeded"}, []atc.Build{
+						{ID: 890, Status: string(atc.StatusSucceeded)},
+						{ID: 889, Status: string(atc.StatusStarted)},
+					})
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "889", Phase: "started"},
+					}))
+				})
+
+				it("stops holding back once more than a page of builds has happened since the earlier one", func() {
+					faketeam := new(fakes.FakeTeam)
+					fakeclient := new(fakes.FakeClient)
+					fakeclient.TeamReturns(faketeam)
+
+					faketeam.JobBuildsReturns(
+						[]atc.Build{
+							{ID: 891, Status: string(atc.StatusSucceeded)},
+							{ID: 890, Status: string(atc.StatusSucceeded)},
+							{ID: 889, Status: string(atc.StatusPending)},
+							{ID: 888, Status: string(atc.StatusStarted)},
+						},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					windowed := source
+					windowed.FetchPageSize = 2
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "887", Phase: "finished", Status: "succeeded"},
+						Source:  windowed,
+					}, fakeclient)
+					response, err := checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "888", Phase: "started"},
+						{BuildId: "890", Phase: "finished", Status: "succeeded"},
+						{BuildId: "891", Phase: "finished", Status: "succeeded"},
+					}))
+				})
+
+				it("returns the earlier build once it finishes, however many builds were held back behind it", func() {
+					faketeam := new(fakes.FakeTeam)
+					fakeclient := new(fakes.FakeClient)
+					fakeclient.TeamReturns(faketeam)
+
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{{ID: 889, Status: string(atc.StatusSucceeded)}, {ID: 888, Status: string(atc.StatusFailed)}},
+						concourse.Pagination{Previous: &concourse.Page{Until: 889, Limit: 2}},
+						true,
+						nil)
+					faketeam.JobBuildsReturnsOnCall(1,
+						[]atc.Build{{ID: 891, Status: string(atc.StatusSucceeded)}, {ID: 890, Status: string(atc.StatusSucceeded)}},
+						concourse.Pagination{Previous: &concourse.Page{Until: 891, Limit: 2}},
+						true,
+						nil)
+					faketeam.JobBuildsReturnsOnCall(2,
+						[]atc.Build{{ID: 892, Status: string(atc.StatusSucceeded)}},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					windowed := source
+					windowed.FetchPageSize = 2
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Version: config.Version{BuildId: "888", Phase: "started"},
+						Source:  windowed,
+					}, fakeclient)
+					response, err := checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+						{BuildId: "888", Phase: "finished", Status: "failed"},
+						{BuildId: "889", Phase: "finished", Status: "succeeded"},
+						{BuildId: "890", Phase: "finished", Status: "succeeded"},
+						{BuildId: "891", Phase: "finished", Status: "succeeded"},
+						{BuildId: "892", Phase: "finished", Status: "succeeded"},
+					}))
+				})
+			}, spec.Nested())
+
+			when("this is the first check and the latest build is running", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.CheckResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturns(
+						[]atc.Build{{ID: 999, Status: string(atc.StatusStarted)}},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					checker := check.NewCheckerUsingClient(&config.CheckRequest{
+						Source: config.Source{
+							ConcourseUrl:   "https://example.com",
+							Team:           "test-team",
+							Pipeline:       "test-pipeline",
+							Job:            "test-job",
+							IncludeRunning: true,
+						},
+					}, fakeclient)
+					var err error
+					response, err = checker.Check()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns it as started", func() {
+					gt.Expect(response).To(gomega.Equal(&config.CheckResponse{{BuildId: "999", Phase: "started"}}))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("build ID is the very first build", func() {
+			gt := gomega.NewGomegaWithT(t)
+			faketeam := new(fakes.FakeTeam)
+			fakeclient := new(fakes.FakeClient)
+			fakeclient.TeamReturns(faketeam)
+			var response *config.CheckResponse
+			source := config.Source{
+				ConcourseUrl: "https://example.com",
+				Team:         "test-team",
+				Pipeline:     "test-pipeline",
+				Job:          "test-job",
+			}
+
+			it.Before(func() {
+				faketeam.JobBuildsReturns(
+					[]atc.Build{{ID: 3, Status: string(atc.StatusFailed)}, {ID: 2, Status: string(atc.StatusSucceeded)}},
+					concourse.Pagination{},
+					true,
+					nil)
+				fakeclient.BuildReturns(atc.Build{ID: 1, Status: string(atc.StatusSucceeded)}, true, nil)
+
+				checker := check.NewCheckerUsingClient(&config.CheckRequest{
+					Version: config.Version{BuildId: "1"},
+					Source:  source,
+				}, fakeclient)
+				var err error
+				response, err = checker.Check()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("pages forwards from after it, as an Until of 0 would give the latest builds", func() {
+				_, _, page := faketeam.JobBuildsArgsForCall(0)
+				gt.Expect(page).To(gomega.Equal(concourse.Page{Until: 1, Limit: 100}))
+			})
+
+			it("fetches it on its own, and returns it with the builds after it", func() {
+				gt.Expect(fakeclient.BuildArgsForCall(0)).To(gomega.Equal("1"))
+				gt.Expect(response).To(gomega.Equal(&config.CheckResponse{
+					{BuildId: "1"},
+					{BuildId: "2", Status: "succeeded"},
+					{BuildId: "3", Status: "failed"},
+				}))
+			})
+		}, spec.Nested())
+
+		when("build ID is below 1", func() {
+			gt := gomega.NewGomegaWithT(t)
+			fakeclient := new(fakes.FakeClient)
+
+			it("returns an error", func() {
+				checker := check.NewCheckerUsingClient(&config.CheckRequest{
+					Version: config.Version{BuildId: "0"},
+					Source:  config.Source{ConcourseUrl: "https://example.com"},
+				}, fakeclient)
+				response, err := checker.Check()
+				gt.Expect(response).To(gomega.BeNil())
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("build id '0' is not a valid build ID"))
+			})
 		}, spec.Nested())
 
 		when("build ID is defined, but is not a valid number", func() {
@@ -0,0 +1,59 @@
+package check
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// namePattern matches pipeline, job or team names. Patterns wrapped in slashes, like `/^deploy-.*$/`, are
+// regular expressions. Anything else is a glob, like `deploy-*`, which matches literal names too.
+type namePattern struct {
+	glob  string
+	regex *regexp.Regexp
+}
+
+func compilePatterns(field string, patterns []string) ([]namePattern, error) {
+	compiled := make([]namePattern, 0, len(patterns))
+
+	for _, p := range patterns {
+		if len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			regex, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				return nil, fmt.Errorf("could not parse regular expression '%s' in %s: %s", p, field, err.Error())
+			}
+
+			compiled = append(compiled, namePattern{regex: regex})
+			continue
+		}
+
+		_, err := path.Match(p, "")
+		if err != nil {
+			return nil, fmt.Errorf("could not parse pattern '%s' in %s: %s", p, field, err.Error())
+		}
+
+		compiled = append(compiled, namePattern{glob: p})
+	}
+
+	return compiled, nil
+}
+
+func (p namePattern) matches(name string) bool {
+	if p.regex != nil {
+		return p.regex.MatchString(name)
+	}
+
+	matched, _ := path.Match(p.glob, name)
+	return matched
+}
+
+func matchesAny(patterns []namePattern, name string) bool {
+	for _, p := range patterns {
+		if p.matches(name) {
+			return true
+		}
+	}
+
+	return false
+}
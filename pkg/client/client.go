@@ -0,0 +1,207 @@
+package client
+
+import (
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+	"strings"
+
+	gc "github.com/concourse/go-concourse/concourse"
+	"golang.org/x/oauth2"
+
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// These are the same client credentials that fly uses when logging in with a username and password.
+const (
+	loginClientId     = "fly"
+	loginClientSecret = "Zmx5"
+)
+
+var loginScopes = []string{"openid", "profile", "email", "federated:id", "groups"}
+
+// New builds a Concourse client from the source configuration. If credentials are given, every request
+// carries a bearer token. For a username and password, that token comes from logging in immediately.
+func New(source config.Source) (gc.Client, error) {
+	tr, err := transport(source)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Transport: tr}
+
+	token, err := authToken(source, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != nil {
+		httpClient = &http.Client{
+			Transport: tokenRejectionTransport{
+				concourseUrl: source.ConcourseUrl,
+				base: &oauth2.Transport{
+					Source: oauth2.StaticTokenSource(token),
+					Base:   tr,
+				},
+			},
+		}
+	}
+
+	return gc.NewClient(source.ConcourseUrl, httpClient, source.EnableTracing), nil
+}
+
+// transport verifies the server's certificate unless told otherwise, trusting ca_cert as well as the
+// system's certificate authorities. A client certificate is presented if one is given.
+func transport(source config.Source) (*http.Transport, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: source.InsecureSkipVerify}
+
+	if source.CaCert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM([]byte(source.CaCert)) {
+			return nil, fmt.Errorf("ca_cert does not contain a valid PEM-encoded certificate")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if source.ClientCert != "" || source.ClientKey != "" {
+		if source.ClientCert == "" || source.ClientKey == "" {
+			return nil, fmt.Errorf("both client_cert and client_key must be set to use a client certificate")
+		}
+
+		certificate, err := tls.X509KeyPair([]byte(source.ClientCert), []byte(source.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("could not load client_cert and client_key: %s", err.Error())
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return &http.Transport{
+		MaxIdleConns:    10,
+		IdleConnTimeout: 30 * time.Second,
+		TLSClientConfig: tlsConfig,
+	}, nil
+}
+
+// tokenRejectionTransport turns a 401 caused by the token into an error of its own. Otherwise go-concourse reports it
+// as "not authorized", which is also what anonymous users see for private jobs. Concourse also gives a 401 to
+// logged-in users for some builds they can't see, such as another team's one-off builds, so the token is only
+// blamed if listing workers, which any valid token may do, is refused as well.
+type tokenRejectionTransport struct {
+	concourseUrl string
+	base         http.RoundTripper
+}
+
+func (t tokenRejectionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	rejected, err := t.tokenIsRejected()
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if !rejected {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+	return nil, fmt.Errorf("token was rejected by '%s', it may have expired or been revoked", t.concourseUrl)
+}
+
+func (t tokenRejectionTransport) tokenIsRejected() (bool, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(t.concourseUrl, "/")+"/api/v1/workers", nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode == http.StatusUnauthorized, nil
+}
+
+func authToken(source config.Source, httpClient *http.Client) (*oauth2.Token, error) {
+	usePassword := source.Username != "" || source.Password != ""
+	useBearerToken := source.BearerToken != ""
+	useTokenFile := source.TokenFile != ""
+
+	methods := 0
+	for _, used := range []bool{usePassword, useBearerToken, useTokenFile} {
+		if used {
+			methods++
+		}
+	}
+	if methods > 1 {
+		return nil, fmt.Errorf("only one of username/password, bearer_token or token_file can be set")
+	}
+
+	switch {
+	case usePassword:
+		return passwordGrant(source, httpClient)
+	case useBearerToken:
+		return bearerToken(source.BearerToken), nil
+	case useTokenFile:
+		contents, err := ioutil.ReadFile(source.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read token_file '%s': %s", source.TokenFile, err.Error())
+		}
+		if strings.TrimSpace(string(contents)) == "" {
+			return nil, fmt.Errorf("token_file '%s' is empty", source.TokenFile)
+		}
+
+		return bearerToken(string(contents)), nil
+	default:
+		return nil, nil
+	}
+}
+
+// bearerToken accepts either a bare token or the "bearer <token>" form which fly keeps in .flyrc.
+func bearerToken(value string) *oauth2.Token {
+	value = strings.TrimSpace(value)
+
+	fields := strings.Fields(value)
+	if len(fields) == 2 && strings.EqualFold(fields[0], "bearer") {
+		value = fields[1]
+	}
+
+	return &oauth2.Token{TokenType: "Bearer", AccessToken: value}
+}
+
+func passwordGrant(source config.Source, httpClient *http.Client) (*oauth2.Token, error) {
+	if source.Username == "" || source.Password == "" {
+		return nil, fmt.Errorf("both username and password must be set to log in to '%s'", source.ConcourseUrl)
+	}
+
+	oauth2Config := oauth2.Config{
+		ClientID:     loginClientId,
+		ClientSecret: loginClientSecret,
+		Endpoint:     oauth2.Endpoint{TokenURL: strings.TrimSuffix(source.ConcourseUrl, "/") + "/sky/token"},
+		Scopes:       loginScopes,
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+	token, err := oauth2Config.PasswordCredentialsToken(ctx, source.Username, source.Password)
+	if err != nil {
+		return nil, fmt.Errorf("could not log in to '%s' as '%s': %s", source.ConcourseUrl, source.Username, err.Error())
+	}
+
+	return token, nil
+}
@@ -0,0 +1,319 @@
+package client_test
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"github.com/concourse/atc"
+	gc "github.com/concourse/go-concourse/concourse"
+
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/client"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+func TestClientPkg(t *testing.T) {
+	spec.Run(t, "pkg/client", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var server *ghttp.Server
+
+		it.Before(func() {
+			server = ghttp.NewServer()
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		when("no credentials are given", func() {
+			var authorization []string
+
+			it.Before(func() {
+				server.AppendHandlers(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					authorization = r.Header["Authorization"]
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode([]atc.Build{})
+				}))
+
+				concourse, err := client.New(config.Source{ConcourseUrl: server.URL()})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				_, _, err = concourse.Builds(gc.Page{})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("sends requests anonymously", func() {
+				gt.Expect(server.ReceivedRequests()).To(gomega.HaveLen(1))
+				gt.Expect(authorization).To(gomega.BeEmpty())
+			})
+		}, spec.Nested())
+
+		when("a username and password are given", func() {
+			when("the login succeeds", func() {
+				var loginRequest *http.Request
+				var authorization string
+
+				it.Before(func() {
+					server.RouteToHandler("POST", "/sky/token", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						r.ParseForm()
+						loginRequest = r
+						w.Header().Set("Content-Type", "application/json")
+						io.WriteString(w, `{"access_token":"test-token","token_type":"Bearer"}`)
+					}))
+					server.RouteToHandler("GET", "/api/v1/builds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						authorization = r.Header.Get("Authorization")
+						w.Header().Set("Content-Type", "application/json")
+						json.NewEncoder(w).Encode([]atc.Build{})
+					}))
+
+					concourse, err := client.New(config.Source{
+						ConcourseUrl: server.URL() + "/",
+						Username:     "test-user",
+						Password:     "test-password",
+					})
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					_, _, err = concourse.Builds(gc.Page{})
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("logs in the same way as fly", func() {
+					clientId, clientSecret, ok := loginRequest.BasicAuth()
+					gt.Expect(ok).To(gomega.BeTrue())
+					gt.Expect(clientId).To(gomega.Equal("fly"))
+					gt.Expect(clientSecret).To(gomega.Equal("Zmx5"))
+					gt.Expect(loginRequest.PostForm.Get("grant_type")).To(gomega.Equal("password"))
+					gt.Expect(loginRequest.PostForm.Get("username")).To(gomega.Equal("test-user"))
+					gt.Expect(loginRequest.PostForm.Get("password")).To(gomega.Equal("test-password"))
+				})
+
+				it("sends the token with later requests", func() {
+					gt.Expect(authorization).To(gomega.Equal("Bearer test-token"))
+				})
+			}, spec.Nested())
+
+			when("the login is rejected", func() {
+				var err error
+
+				it.Before(func() {
+					server.RouteToHandler("POST", "/sky/token", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusUnauthorized)
+					}))
+
+					_, err = client.New(config.Source{
+						ConcourseUrl: server.URL(),
+						Username:     "test-user",
+						Password:     "wrong-password",
+					})
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err.Error()).To(gomega.ContainSubstring("could not log in to '%s' as 'test-user'", server.URL()))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("a bearer token is given", func() {
+			var authorization string
+
+			it.Before(func() {
+				server.RouteToHandler("GET", "/api/v1/builds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					authorization = r.Header.Get("Authorization")
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode([]atc.Build{})
+				}))
+
+				concourse, err := client.New(config.Source{ConcourseUrl: server.URL(), BearerToken: "static-token"})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				_, _, err = concourse.Builds(gc.Page{})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("sends the token with every request", func() {
+				gt.Expect(authorization).To(gomega.Equal("Bearer static-token"))
+			})
+		}, spec.Nested())
+
+		when("a token file is given", func() {
+			var authorization string
+			var tokenFile string
+
+			it.Before(func() {
+				file, err := ioutil.TempFile("", "token")
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				_, err = file.WriteString("bearer token-from-file\n")
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				file.Close()
+				tokenFile = file.Name()
+
+				server.RouteToHandler("GET", "/api/v1/builds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					authorization = r.Header.Get("Authorization")
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode([]atc.Build{})
+				}))
+			})
+
+			it.After(func() {
+				os.Remove(tokenFile)
+			})
+
+			it("reads the token from the file and sends it with every request", func() {
+				concourse, err := client.New(config.Source{ConcourseUrl: server.URL(), TokenFile: tokenFile})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				_, _, err = concourse.Builds(gc.Page{})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				gt.Expect(authorization).To(gomega.Equal("Bearer token-from-file"))
+			})
+
+			it("returns an error if the file cannot be read", func() {
+				_, err := client.New(config.Source{ConcourseUrl: server.URL(), TokenFile: tokenFile + "-missing"})
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("could not read token_file"))
+			})
+		}, spec.Nested())
+
+		when("the token is rejected by the server", func() {
+			var err error
+
+			it.Before(func() {
+				server.RouteToHandler("GET", "/api/v1/builds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusUnauthorized)
+				}))
+				server.RouteToHandler("GET", "/api/v1/workers", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusUnauthorized)
+				}))
+
+				concourse, clientErr := client.New(config.Source{ConcourseUrl: server.URL(), BearerToken: "expired-token"})
+				gt.Expect(clientErr).NotTo(gomega.HaveOccurred())
+
+				_, _, err = concourse.Builds(gc.Page{})
+			})
+
+			it("returns an error which is distinct from an anonymous 'not authorized'", func() {
+				gt.Expect(err).NotTo(gomega.Equal(gc.ErrUnauthorized))
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("token was rejected by '%s', it may have expired", server.URL()))
+			})
+		}, spec.Nested())
+
+		when("the token is accepted, but not for what was asked for", func() {
+			var err error
+			var workersAuthorization string
+
+			it.Before(func() {
+				server.RouteToHandler("GET", "/api/v1/builds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusUnauthorized)
+				}))
+				server.RouteToHandler("GET", "/api/v1/workers", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					workersAuthorization = r.Header.Get("Authorization")
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode([]atc.Worker{})
+				}))
+
+				concourse, clientErr := client.New(config.Source{ConcourseUrl: server.URL(), BearerToken: "valid-token"})
+				gt.Expect(clientErr).NotTo(gomega.HaveOccurred())
+
+				_, _, err = concourse.Builds(gc.Page{})
+			})
+
+			it("checks the token by listing workers with it", func() {
+				gt.Expect(workersAuthorization).To(gomega.Equal("Bearer valid-token"))
+			})
+
+			it("returns 'not authorized', as for an anonymous user", func() {
+				gt.Expect(err).To(gomega.Equal(gc.ErrUnauthorized))
+			})
+		}, spec.Nested())
+
+		when("more than one kind of credential is given", func() {
+			it("returns an error without contacting the server", func() {
+				_, err := client.New(config.Source{
+					ConcourseUrl: server.URL(),
+					Username:     "test-user",
+					Password:     "test-password",
+					BearerToken:  "static-token",
+				})
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("only one of username/password, bearer_token or token_file can be set"))
+				gt.Expect(server.ReceivedRequests()).To(gomega.BeEmpty())
+			})
+		}, spec.Nested())
+
+		when("the server uses TLS", func() {
+			var tlsServer *ghttp.Server
+			var serverCaCert string
+
+			it.Before(func() {
+				tlsServer = ghttp.NewTLSServer()
+				tlsServer.RouteToHandler("GET", "/api/v1/builds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode([]atc.Build{})
+				}))
+
+				serverCaCert = string(pem.EncodeToMemory(&pem.Block{
+					Type:  "CERTIFICATE",
+					Bytes: tlsServer.HTTPTestServer.Certificate().Raw,
+				}))
+			})
+
+			it.After(func() {
+				tlsServer.Close()
+			})
+
+			it("verifies the server certificate by default", func() {
+				concourse, err := client.New(config.Source{ConcourseUrl: tlsServer.URL()})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				_, _, err = concourse.Builds(gc.Page{})
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("certificate"))
+			})
+
+			it("trusts the certificate authority given in ca_cert", func() {
+				concourse, err := client.New(config.Source{ConcourseUrl: tlsServer.URL(), CaCert: serverCaCert})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				_, _, err = concourse.Builds(gc.Page{})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("skips verification when insecure_skip_verify is set", func() {
+				concourse, err := client.New(config.Source{ConcourseUrl: tlsServer.URL(), InsecureSkipVerify: true})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				_, _, err = concourse.Builds(gc.Page{})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("returns an error if ca_cert is not a PEM certificate", func() {
+				_, err := client.New(config.Source{ConcourseUrl: tlsServer.URL(), CaCert: "not a certificate"})
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("ca_cert does not contain a valid PEM-encoded certificate"))
+			})
+
+			it("returns an error if only one of client_cert or client_key is given", func() {
+				_, err := client.New(config.Source{ConcourseUrl: tlsServer.URL(), ClientCert: serverCaCert})
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("both client_cert and client_key must be set"))
+			})
+
+			it("returns an error if the client certificate cannot be loaded", func() {
+				_, err := client.New(config.Source{ConcourseUrl: tlsServer.URL(), ClientCert: serverCaCert, ClientKey: "not a key"})
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("could not load client_cert and client_key"))
+			})
+		}, spec.Nested())
+
+		when("only one of username or password is given", func() {
+			it("returns an error without contacting the server", func() {
+				_, err := client.New(config.Source{ConcourseUrl: server.URL(), Username: "test-user"})
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("both username and password must be set"))
+				gt.Expect(server.ReceivedRequests()).To(gomega.BeEmpty())
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
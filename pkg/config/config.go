@@ -1,20 +1,116 @@
 package config
 
+import (
+	"github.com/concourse/atc"
+
+	"fmt"
+	"strconv"
+)
+
 type Source struct {
-	ConcourseUrl   string `json:"concourse_url"`
-	Team           string `json:"team"`
-	Pipeline       string `json:"pipeline"`
-	Job            string `json:"job,omitempty"`
-	InitialBuildId int    `json:"initial_build_id,omitempty"`
-	FetchPageSize  int    `json:"fetch_page_size,omitempty"`
-	EnableTracing  bool   `json:"enable_tracing,omitempty"`
+	ConcourseUrl       string   `json:"concourse_url"`
+	Team               string   `json:"team"`
+	Pipeline           string   `json:"pipeline"`
+	Job                string   `json:"job,omitempty"`
+	Pipelines          []string `json:"pipelines,omitempty"`
+	Jobs               []string `json:"jobs,omitempty"`
+	InitialBuildId     int      `json:"initial_build_id,omitempty"`
+	InitialBuildSince  string   `json:"initial_build_since,omitempty"`
+	FetchPageSize      int      `json:"fetch_page_size,omitempty"`
+	EnableTracing      bool     `json:"enable_tracing,omitempty"`
+	Username           string   `json:"username,omitempty"`
+	Password           string   `json:"password,omitempty"`
+	BearerToken        string   `json:"bearer_token,omitempty"`
+	TokenFile          string   `json:"token_file,omitempty"`
+	CaCert             string   `json:"ca_cert,omitempty"`
+	ClientCert         string   `json:"client_cert,omitempty"`
+	ClientKey          string   `json:"client_key,omitempty"`
+	InsecureSkipVerify bool     `json:"insecure_skip_verify,omitempty"`
+	Statuses           []string `json:"statuses,omitempty"`
+	ExcludePipelines   []string `json:"exclude_pipelines,omitempty"`
+	ExcludeJobs        []string `json:"exclude_jobs,omitempty"`
+	ExcludeTeams       []string `json:"exclude_teams,omitempty"`
+	OneOffBuilds       string   `json:"one_off_builds,omitempty"`
+	IncludeRunning     bool     `json:"include_running,omitempty"`
 }
 
+// String keeps credentials out of traces, which print the whole request.
+func (s Source) String() string {
+	type redactedSource Source
+	redacted := redactedSource(s)
+	if redacted.Password != "" {
+		redacted.Password = "[redacted]"
+	}
+	if redacted.BearerToken != "" {
+		redacted.BearerToken = "[redacted]"
+	}
+	if redacted.ClientKey != "" {
+		redacted.ClientKey = "[redacted]"
+	}
+
+	return fmt.Sprintf("%+v", redacted)
+}
+
+// Version identifies a build by BuildId. The other fields are there to make versions readable in the Concourse
+// UI, and are missing from versions produced by earlier releases. Phase is only set when include_running is on,
+// so that a build produces one version when it starts and another when it finishes.
 type Version struct {
-	BuildId string `json:"build_id"`
+	BuildId   string `json:"build_id"`
+	Phase     string `json:"phase,omitempty"`
+	Team      string `json:"team,omitempty"`
+	Pipeline  string `json:"pipeline,omitempty"`
+	Job       string `json:"job,omitempty"`
+	JobNumber string `json:"job_number,omitempty"`
+	Status    string `json:"status,omitempty"`
 }
 
-type InParams struct{}
+const (
+	PhaseStarted  = "started"
+	PhaseFinished = "finished"
+)
+
+// VersionOf describes a build as it is now. The status is only given once it is final, so a version from out can
+// lack a status which check would give the same build later. check gives back the version it was given when the
+// build's phase hasn't changed, so that Concourse doesn't see the same build twice.
+func VersionOf(build atc.Build, includeRunning bool) Version {
+	version := Version{
+		BuildId:   strconv.Itoa(build.ID),
+		Team:      build.TeamName,
+		Pipeline:  build.PipelineName,
+		Job:       build.JobName,
+		JobNumber: build.Name,
+	}
+
+	running := build.Status == string(atc.StatusStarted) || build.Status == string(atc.StatusPending)
+	if !running {
+		version.Status = build.Status
+	}
+
+	if !includeRunning {
+		return version
+	}
+
+	if running {
+		version.Phase = PhaseStarted
+	} else {
+		version.Phase = PhaseFinished
+	}
+
+	return version
+}
+
+type InParams struct {
+	// Fetch lists what to fetch, out of build, resources, plan, job, versioned_resource_types and events. The build
+	// is fetched regardless, and everything is fetched if Fetch is empty.
+	Fetch      []string `json:"fetch,omitempty"`
+	SkipEvents bool     `json:"skip_events,omitempty"`
+
+	// PlainLogOnly leaves out events.log, which has terminal colours in it, in favour of events.plain.log.
+	PlainLogOnly bool `json:"plain_log_only,omitempty"`
+
+	// TimestampedLog is absolute or relative, to also write events.timestamped.log with timestamps of that kind.
+	TimestampedLog string `json:"timestamped_log,omitempty"`
+}
 
 type VersionMetadataField struct {
 	Name  string `json:"name"`
@@ -43,3 +139,34 @@ type CheckRequest struct {
 }
 
 type CheckResponse []Version
+
+type OutParams struct {
+	Action   string `json:"action"`
+	Team     string `json:"team,omitempty"`
+	Pipeline string `json:"pipeline,omitempty"`
+	Job      string `json:"job,omitempty"`
+
+	// Build is the directory of an earlier get of this resource, for actions which act on that build.
+	Build      string `json:"build,omitempty"`
+	AllRunning bool   `json:"all_running,omitempty"`
+
+	// Resource is a resource in the pipeline, to pin to either Version or the version in VersionFile.
+	Resource    string            `json:"resource,omitempty"`
+	Version     map[string]string `json:"version,omitempty"`
+	VersionFile string            `json:"version_file,omitempty"`
+
+	// Wait follows a triggered build until it finishes, for up to WaitTimeout.
+	Wait        bool   `json:"wait,omitempty"`
+	WaitTimeout string `json:"wait_timeout,omitempty"`
+}
+
+type OutRequest struct {
+	Source           Source    `json:"source"`
+	Params           OutParams `json:"params"`
+	WorkingDirectory string    `json:"working_directory,omitempty"`
+}
+
+type OutResponse struct {
+	Version  Version                `json:"version"`
+	Metadata []VersionMetadataField `json:"metadata"`
+}
@@ -0,0 +1,235 @@
+package in
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// ansiStyle is the graphic rendition set by SGR escape sequences, which is how terminal colours are written. It
+// carries over from one line to the next, as it would in a terminal.
+type ansiStyle struct {
+	bold      bool
+	faint     bool
+	italic    bool
+	underline bool
+	fg        ansiColour
+	bg        ansiColour
+}
+
+// ansiColour is one of the 16 standard colours, which are styled by the page, or any other colour as RGB.
+type ansiColour struct {
+	set   bool
+	index int
+	rgb   string
+}
+
+// ansiLineToHTML converts a line of terminal output into HTML, with colours as styled spans. Other escape
+// sequences are dropped, and only what follows the last carriage return is kept, as a terminal would have drawn
+// over everything before it.
+func ansiLineToHTML(style *ansiStyle, line []byte) string {
+	var converted strings.Builder
+	var text []byte
+
+	flush := func() {
+		if len(text) == 0 {
+			return
+		}
+
+		open := style.span()
+		if open == "" {
+			converted.WriteString(html.EscapeString(string(text)))
+		} else {
+			converted.WriteString(open + html.EscapeString(string(text)) + "</span>")
+		}
+		text = text[:0]
+	}
+
+	for i := 0; i < len(line); i++ {
+		b := line[i]
+		switch {
+		case b == '\x1b':
+			flush()
+			i = style.applyEscape(line, i)
+		case b == '\r':
+			if i == len(line)-1 {
+				continue
+			}
+			converted.Reset()
+			text = text[:0]
+		case b == '\t' || (b >= ' ' && b != '\x7f'):
+			text = append(text, b)
+		}
+	}
+	flush()
+
+	return converted.String()
+}
+
+// applyEscape applies the escape sequence starting at start, if it sets colours, and returns where it ends.
+func (s *ansiStyle) applyEscape(line []byte, start int) int {
+	if start+1 >= len(line) {
+		return start
+	}
+
+	switch line[start+1] {
+	case '[':
+		end := start + 2
+		for end < len(line) && line[end] >= '0' && line[end] <= '?' {
+			end++
+		}
+		params := string(line[start+2 : end])
+		intermediates := end
+		for end < len(line) && line[end] >= ' ' && line[end] <= '/' {
+			end++
+		}
+		if end >= len(line) {
+			return len(line) - 1
+		}
+		if line[end] == 'm' && end == intermediates {
+			s.applySGR(params)
+		}
+		return end
+	case ']':
+		for end := start + 2; end < len(line); end++ {
+			if line[end] == '\x07' {
+				return end
+			}
+			if line[end] == '\x1b' && end+1 < len(line) && line[end+1] == '\\' {
+				return end + 1
+			}
+		}
+		return len(line) - 1
+	case '(', ')', '*', '+':
+		if start+2 >= len(line) {
+			return len(line) - 1
+		}
+		return start + 2
+	default:
+		return start + 1
+	}
+}
+
+func (s *ansiStyle) applySGR(params string) {
+	var codes []int
+	for _, param := range strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' }) {
+		code, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		codes = []int{0}
+	}
+
+	for i := 0; i < len(codes); i++ {
+		code := codes[i]
+		switch {
+		case code == 0:
+			*s = ansiStyle{}
+		case code == 1:
+			s.bold = true
+		case code == 2:
+			s.faint = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 22:
+			s.bold, s.faint = false, false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code >= 30 && code <= 37:
+			s.fg = ansiColour{set: true, index: code - 30}
+		case code == 38:
+			s.fg, i = extendedColour(codes, i)
+		case code == 39:
+			s.fg = ansiColour{}
+		case code >= 40 && code <= 47:
+			s.bg = ansiColour{set: true, index: code - 40}
+		case code == 48:
+			s.bg, i = extendedColour(codes, i)
+		case code == 49:
+			s.bg = ansiColour{}
+		case code >= 90 && code <= 97:
+			s.fg = ansiColour{set: true, index: code - 90 + 8}
+		case code >= 100 && code <= 107:
+			s.bg = ansiColour{set: true, index: code - 100 + 8}
+		}
+	}
+}
+
+// extendedColour reads a 256-colour (38;5;n) or RGB (38;2;r;g;b) colour following codes[at], and returns it with
+// the index of the last code it used.
+func extendedColour(codes []int, at int) (ansiColour, int) {
+	if at+2 < len(codes) && codes[at+1] == 5 {
+		return colour256(codes[at+2]), at + 2
+	}
+	if at+4 < len(codes) && codes[at+1] == 2 {
+		return ansiColour{set: true, rgb: fmt.Sprintf("#%02x%02x%02x", codes[at+2]&0xff, codes[at+3]&0xff, codes[at+4]&0xff)}, at + 4
+	}
+
+	return ansiColour{}, len(codes)
+}
+
+// colour256 is a colour from the xterm 256-colour palette: the 16 standard colours, a 6x6x6 cube and a grey ramp.
+func colour256(n int) ansiColour {
+	switch {
+	case n < 0 || n > 255:
+		return ansiColour{}
+	case n < 16:
+		return ansiColour{set: true, index: n}
+	case n < 232:
+		levels := []int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return ansiColour{set: true, rgb: fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[n/6%6], levels[n%6])}
+	default:
+		grey := 8 + 10*(n-232)
+		return ansiColour{set: true, rgb: fmt.Sprintf("#%02x%02x%02x", grey, grey, grey)}
+	}
+}
+
+// span opens a span for the style, or is empty if the text is unstyled.
+func (s *ansiStyle) span() string {
+	var classes, styles []string
+	for _, flag := range []struct {
+		on    bool
+		class string
+	}{{s.bold, "ansi-bold"}, {s.faint, "ansi-faint"}, {s.italic, "ansi-italic"}, {s.underline, "ansi-underline"}} {
+		if flag.on {
+			classes = append(classes, flag.class)
+		}
+	}
+
+	for _, colour := range []struct {
+		colour   ansiColour
+		class    string
+		property string
+	}{{s.fg, "ansi-fg-%d", "color"}, {s.bg, "ansi-bg-%d", "background-color"}} {
+		switch {
+		case !colour.colour.set:
+		case colour.colour.rgb != "":
+			styles = append(styles, fmt.Sprintf("%s:%s", colour.property, colour.colour.rgb))
+		default:
+			classes = append(classes, fmt.Sprintf(colour.class, colour.colour.index))
+		}
+	}
+
+	if len(classes) == 0 && len(styles) == 0 {
+		return ""
+	}
+
+	open := "<span"
+	if len(classes) > 0 {
+		open += fmt.Sprintf(` class="%s"`, strings.Join(classes, " "))
+	}
+	if len(styles) > 0 {
+		open += fmt.Sprintf(` style="%s"`, strings.Join(styles, ";"))
+	}
+
+	return open + ">"
+}
@@ -0,0 +1,58 @@
+package in
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+)
+
+func TestAnsiLineToHTML(t *testing.T) {
+	spec.Run(t, "ansiLineToHTML", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var style *ansiStyle
+
+		it.Before(func() {
+			style = &ansiStyle{}
+		})
+
+		convert := func(line string) string {
+			return ansiLineToHTML(style, []byte(line))
+		}
+
+		it("escapes HTML", func() {
+			gt.Expect(convert(`<script>alert("hi") & bye</script>`)).To(gomega.Equal(`&lt;script&gt;alert(&#34;hi&#34;) &amp; bye&lt;/script&gt;`))
+		})
+
+		it("turns the standard colours into classes", func() {
+			gt.Expect(convert("\x1b[1mrunning\x1b[0m \x1b[32;1mok\x1b[0m \x1b[91;44mbad\x1b[0m")).To(gomega.Equal(
+				`<span class="ansi-bold">running</span> <span class="ansi-bold ansi-fg-2">ok</span> <span class="ansi-fg-9 ansi-bg-4">bad</span>`,
+			))
+		})
+
+		it("turns 256-colour and RGB colours into styles", func() {
+			gt.Expect(convert("\x1b[38;5;208morange\x1b[39m \x1b[48;2;1;2;3mdark\x1b[m")).To(gomega.Equal(
+				`<span style="color:#ff8700">orange</span> <span style="background-color:#010203">dark</span>`,
+			))
+		})
+
+		it("carries the style over to the next line", func() {
+			gt.Expect(convert("\x1b[31mstarts red")).To(gomega.Equal(`<span class="ansi-fg-1">starts red</span>`))
+			gt.Expect(convert("still red\x1b[0m")).To(gomega.Equal(`<span class="ansi-fg-1">still red</span>`))
+			gt.Expect(convert("plain")).To(gomega.Equal("plain"))
+		})
+
+		it("drops other escape sequences and control characters", func() {
+			gt.Expect(convert("\x1b[2K\x1b]0;title\x07\x1b(Bdone\x07\tok")).To(gomega.Equal("done\tok"))
+		})
+
+		it("keeps only the last redraw of a progress line", func() {
+			gt.Expect(convert("\x1b[33m 10%\r 50%\r100%\r")).To(gomega.Equal(`<span class="ansi-fg-3">100%</span>`))
+		})
+
+		it("copes with sequences cut off at the end of the line", func() {
+			gt.Expect(convert("text\x1b[3")).To(gomega.Equal("text"))
+			gt.Expect(convert("text\x1b")).To(gomega.Equal("text"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
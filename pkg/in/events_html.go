@@ -0,0 +1,281 @@
+package in
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+)
+
+// eventsHtmlHeader describes the build at the top of events.html, from build.json and resources.json.
+type eventsHtmlHeader struct {
+	Title    string
+	BuildUrl string
+	Status   string
+	Started  string
+	Ended    string
+	Duration string
+	Inputs   []eventsHtmlResource
+	Outputs  []eventsHtmlResource
+}
+
+type eventsHtmlResource struct {
+	Name    string
+	Type    string
+	Version string
+}
+
+// versionString shows a version the same way as the input_<name> metadata.
+func versionString(version atc.Version) string {
+	encoded, err := json.Marshal(version)
+	if err != nil {
+		return ""
+	}
+
+	return string(encoded)
+}
+
+var eventsHtmlHeaderTemplate = template.Must(template.New("header").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { background: #1d1f21; color: #e6e7e8; font-family: sans-serif; margin: 2em; }
+a { color: #81a2be; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { text-align: left; padding: 0.2em 1em 0.2em 0; vertical-align: top; }
+details { margin: 0.5em 0; }
+summary { cursor: pointer; font-weight: bold; padding: 0.3em; background: #2f3033; }
+pre.log { margin: 0; padding: 0.5em; background: #111213; white-space: pre-wrap; word-wrap: break-word; }
+.status { font-weight: bold; }
+.status-succeeded { color: #11c560; }
+.status-failed { color: #ed4b35; }
+.status-errored { color: #f5a623; }
+.status-aborted { color: #8b572a; }
+.ansi-bold { font-weight: bold; }
+.ansi-faint { opacity: 0.6; }
+.ansi-italic { font-style: italic; }
+.ansi-underline { text-decoration: underline; }
+.ansi-fg-0 { color: #1d1f21; } .ansi-bg-0 { background-color: #1d1f21; }
+.ansi-fg-1 { color: #cc6666; } .ansi-bg-1 { background-color: #cc6666; }
+.ansi-fg-2 { color: #b5bd68; } .ansi-bg-2 { background-color: #b5bd68; }
+.ansi-fg-3 { color: #f0c674; } .ansi-bg-3 { background-color: #f0c674; }
+.ansi-fg-4 { color: #81a2be; } .ansi-bg-4 { background-color: #81a2be; }
+.ansi-fg-5 { color: #b294bb; } .ansi-bg-5 { background-color: #b294bb; }
+.ansi-fg-6 { color: #8abeb7; } .ansi-bg-6 { background-color: #8abeb7; }
+.ansi-fg-7 { color: #c5c8c6; } .ansi-bg-7 { background-color: #c5c8c6; }
+.ansi-fg-8 { color: #666666; } .ansi-bg-8 { background-color: #666666; }
+.ansi-fg-9 { color: #d54e53; } .ansi-bg-9 { background-color: #d54e53; }
+.ansi-fg-10 { color: #b9ca4a; } .ansi-bg-10 { background-color: #b9ca4a; }
+.ansi-fg-11 { color: #e7c547; } .ansi-bg-11 { background-color: #e7c547; }
+.ansi-fg-12 { color: #7aa6da; } .ansi-bg-12 { background-color: #7aa6da; }
+.ansi-fg-13 { color: #c397d8; } .ansi-bg-13 { background-color: #c397d8; }
+.ansi-fg-14 { color: #70c0b1; } .ansi-bg-14 { background-color: #70c0b1; }
+.ansi-fg-15 { color: #eaeaea; } .ansi-bg-15 { background-color: #eaeaea; }
+</style>
+</head>
+<body>
+<h1>{{if .BuildUrl}}<a href="{{.BuildUrl}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h1>
+<table>
+<tr><th>status</th><td class="status status-{{.Status}}">{{.Status}}</td></tr>
+{{- if .Started}}
+<tr><th>started</th><td>{{.Started}}</td></tr>
+{{- end}}
+{{- if .Ended}}
+<tr><th>ended</th><td>{{.Ended}}</td></tr>
+<tr><th>duration</th><td>{{.Duration}}</td></tr>
+{{- end}}
+</table>
+{{- if .Inputs}}
+<h2>Inputs</h2>
+<table>
+{{- range .Inputs}}
+<tr><th>{{.Name}}</th><td>{{.Type}}</td><td>{{.Version}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Outputs}}
+<h2>Outputs</h2>
+<table>
+{{- range .Outputs}}
+<tr><th>{{.Name}}</th><td>{{.Type}}</td><td>{{.Version}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<h2>Log</h2>
+`))
+
+// eventsHtmlWriter writes events.html: a page with the build's details, and its output with terminal colours
+// turned into CSS. Output is in a collapsible section per step, where steps are known from the plan. Lines are
+// written in the order they arrived, so a step whose output is interleaved with another's, as happens under an
+// aggregate, gets a section for each run of lines.
+type eventsHtmlWriter struct {
+	file   *os.File
+	buffer *bufio.Writer
+	titles map[event.OriginID]string
+
+	streams    map[string]*htmlStream
+	order      []string
+	section    string
+	inSection  bool
+	lastStatus string
+}
+
+// htmlStream is the output of one step so far, up to the end of its last full line.
+type htmlStream struct {
+	style ansiStyle
+	line  []byte
+}
+
+func newEventsHtmlWriter(path string, header eventsHtmlHeader, titles map[event.OriginID]string) (*eventsHtmlWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &eventsHtmlWriter{
+		file:    file,
+		buffer:  bufio.NewWriter(file),
+		titles:  titles,
+		streams: make(map[string]*htmlStream),
+	}
+
+	err = eventsHtmlHeaderTemplate.Execute(w.buffer, header)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not write the header of '%s': %s", path, err.Error())
+	}
+
+	return w, nil
+}
+
+func (w *eventsHtmlWriter) write(envelope eventEnvelope) error {
+	if status, ok := envelope.Data.(event.Status); ok {
+		if status.Status != atc.StatusStarted {
+			w.lastStatus = string(status.Status)
+		}
+		return nil
+	}
+
+	origin, text, output := stepOutput(envelope.Data)
+	if !output {
+		return nil
+	}
+
+	// output from anything not in the plan goes together, without a section of its own
+	title := w.titles[origin.ID]
+	stream, ok := w.streams[title]
+	if !ok {
+		stream = &htmlStream{}
+		w.streams[title] = stream
+		w.order = append(w.order, title)
+	}
+
+	for _, b := range []byte(text) {
+		if b != '\n' {
+			stream.line = append(stream.line, b)
+			continue
+		}
+
+		err := w.writeLine(title, stream)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *eventsHtmlWriter) writeLine(title string, stream *htmlStream) error {
+	err := w.startSection(title)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.buffer.WriteString(ansiLineToHTML(&stream.style, stream.line) + "\n")
+	stream.line = stream.line[:0]
+	return err
+}
+
+func (w *eventsHtmlWriter) startSection(title string) error {
+	if w.inSection && w.section == title {
+		return nil
+	}
+
+	err := w.endSection()
+	if err != nil {
+		return err
+	}
+
+	if title == "" {
+		_, err = w.buffer.WriteString(`<pre class="log">`)
+	} else {
+		_, err = fmt.Fprintf(w.buffer, `<details open><summary>%s</summary><pre class="log">`, html.EscapeString(title))
+	}
+	w.section, w.inSection = title, true
+	return err
+}
+
+func (w *eventsHtmlWriter) endSection() error {
+	if !w.inSection {
+		return nil
+	}
+
+	w.inSection = false
+	if w.section == "" {
+		_, err := w.buffer.WriteString("</pre>\n")
+		return err
+	}
+	_, err := w.buffer.WriteString("</pre></details>\n")
+	return err
+}
+
+// close writes out lines which didn't end in a newline, and the status the build finished with, if it has.
+func (w *eventsHtmlWriter) close() error {
+	err := w.finish()
+	if err != nil {
+		w.file.Close()
+		return err
+	}
+
+	return w.file.Close()
+}
+
+func (w *eventsHtmlWriter) finish() error {
+	for _, title := range w.order {
+		stream := w.streams[title]
+		if len(stream.line) == 0 {
+			continue
+		}
+
+		err := w.writeLine(title, stream)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := w.endSection()
+	if err != nil {
+		return err
+	}
+
+	if w.lastStatus != "" {
+		_, err = fmt.Fprintf(w.buffer, "<p class=\"status status-%s\">%s</p>\n", html.EscapeString(w.lastStatus), html.EscapeString(w.lastStatus))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = w.buffer.WriteString("</body>\n</html>\n")
+	if err != nil {
+		return err
+	}
+
+	return w.buffer.Flush()
+}
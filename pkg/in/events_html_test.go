@@ -0,0 +1,83 @@
+package in
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func TestEventsHtml(t *testing.T) {
+	spec.Run(t, "events.html", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var dir string
+		var page string
+
+		it.Before(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "events-html")
+			gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+			path := filepath.Join(dir, "events.html")
+			w, err := newEventsHtmlWriter(path, eventsHtmlHeader{
+				Title:    "team/pipeline/job #111",
+				BuildUrl: "https://example.com/teams/team/pipelines/pipeline/jobs/job/builds/111",
+				Status:   "failed",
+				Inputs:   []eventsHtmlResource{{Name: "source-code", Type: "git", Version: `{"ref":"abc123"}`}},
+			}, map[event.OriginID]string{
+				"g1": "get: source-code",
+				"t1": "task: unit",
+			})
+			gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+			for _, ev := range []atc.Event{
+				event.Status{Status: atc.StatusStarted},
+				event.Log{Origin: event.Origin{ID: "g1"}, Payload: "fetched <abc123>\n"},
+				event.StartTask{Origin: event.Origin{ID: "t1"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "make"}}},
+				event.Log{Origin: event.Origin{ID: "t1"}, Payload: "\x1b[31mFAIL\x1b[0m: "},
+				event.Log{Origin: event.Origin{ID: "t1"}, Payload: "TestThing\n"},
+				event.Log{Origin: event.Origin{ID: "unknown"}, Payload: "from nowhere\n"},
+				event.Log{Origin: event.Origin{ID: "t1"}, Payload: "exit 1"},
+				event.Status{Status: atc.StatusFailed},
+			} {
+				gt.Expect(w.write(eventEnvelope{Data: ev, Event: ev.EventType(), Version: ev.Version()})).To(gomega.Succeed())
+			}
+			gt.Expect(w.close()).To(gomega.Succeed())
+
+			contents, err := ioutil.ReadFile(path)
+			gt.Expect(err).NotTo(gomega.HaveOccurred())
+			page = string(contents)
+		})
+
+		it.After(func() {
+			os.RemoveAll(dir)
+		})
+
+		it("has a header with the build and its resources", func() {
+			gt.Expect(page).To(gomega.HavePrefix("<!DOCTYPE html>"))
+			gt.Expect(page).To(gomega.ContainSubstring(`<h1><a href="https://example.com/teams/team/pipelines/pipeline/jobs/job/builds/111">team/pipeline/job #111</a></h1>`))
+			gt.Expect(page).To(gomega.ContainSubstring(`<td class="status status-failed">failed</td>`))
+			gt.Expect(page).To(gomega.ContainSubstring(`<tr><th>source-code</th><td>git</td><td>{&#34;ref&#34;:&#34;abc123&#34;}</td></tr>`))
+		})
+
+		it("puts each step's output into a collapsible section, in the order it arrived", func() {
+			gt.Expect(page).To(gomega.ContainSubstring(
+				"<details open><summary>get: source-code</summary><pre class=\"log\">fetched &lt;abc123&gt;\n</pre></details>\n" +
+					"<details open><summary>task: unit</summary><pre class=\"log\"><span class=\"ansi-bold\">running make</span>\n" +
+					"<span class=\"ansi-fg-1\">FAIL</span>: TestThing\n</pre></details>\n" +
+					"<pre class=\"log\">from nowhere\n</pre>\n" +
+					"<details open><summary>task: unit</summary><pre class=\"log\">exit 1\n</pre></details>\n",
+			))
+		})
+
+		it("ends with the status the build finished with", func() {
+			gt.Expect(page).To(gomega.HaveSuffix("<p class=\"status status-failed\">failed</p>\n</body>\n</html>\n"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
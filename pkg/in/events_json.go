@@ -0,0 +1,74 @@
+package in
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// eventsJsonWriter writes events.json one event at a time, so that builds with huge logs never have all of their
+// events in memory at once. The file has the same shape as other JSON files: the resource's metadata, then an
+// "events" array.
+type eventsJsonWriter struct {
+	file    *os.File
+	buffer  *bufio.Writer
+	written int
+}
+
+func newEventsJsonWriter(path string, metadataPrefix string) (*eventsJsonWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &eventsJsonWriter{
+		file:   file,
+		buffer: bufio.NewWriter(file),
+	}
+
+	_, err = w.buffer.WriteString(metadataPrefix + `"events":[`)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *eventsJsonWriter) write(envelope eventEnvelope) error {
+	encoded, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("could not encode '%s' event into 'events': %s", envelope.Event, err.Error())
+	}
+
+	if w.written > 0 {
+		err = w.buffer.WriteByte(',')
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = w.buffer.Write(encoded)
+	if err != nil {
+		return err
+	}
+	w.written++
+
+	return nil
+}
+
+// close finishes the document. It needs to be called even if writing failed, to close the file.
+func (w *eventsJsonWriter) close() error {
+	_, err := w.buffer.WriteString("]}\n")
+	if err == nil {
+		err = w.buffer.Flush()
+	}
+
+	closeErr := w.file.Close()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
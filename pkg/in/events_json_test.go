@@ -0,0 +1,74 @@
+package in
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func TestEventsJsonWriter(t *testing.T) {
+	spec.Run(t, "eventsJsonWriter", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var dir, path string
+
+		it.Before(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "events-json")
+			gt.Expect(err).NotTo(gomega.HaveOccurred())
+			path = filepath.Join(dir, "events.json")
+		})
+
+		it.After(func() {
+			os.RemoveAll(dir)
+		})
+
+		writeEvents := func(events ...atc.Event) string {
+			w, err := newEventsJsonWriter(path, `{"concourse_build_resource":{"release":"v0.99.11"},`)
+			gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+			for _, ev := range events {
+				gt.Expect(w.write(eventEnvelope{Data: ev, Event: ev.EventType(), Version: ev.Version()})).To(gomega.Succeed())
+			}
+			gt.Expect(w.close()).To(gomega.Succeed())
+
+			contents, err := ioutil.ReadFile(path)
+			gt.Expect(err).NotTo(gomega.HaveOccurred())
+			return string(contents)
+		}
+
+		when("there are events", func() {
+			it("writes them as an array after the metadata", func() {
+				written := writeEvents(event.Log{Payload: "first"}, event.Status{Status: atc.StatusSucceeded})
+
+				gt.Expect(written).To(gomega.Equal(
+					`{"concourse_build_resource":{"release":"v0.99.11"},"events":[` +
+						`{"data":{"time":0,"origin":{},"payload":"first"},"event":"log","version":"5.1"},` +
+						`{"data":{"status":"succeeded","time":0},"event":"status","version":"1.0"}` +
+						"]}\n",
+				))
+			})
+
+			it("writes valid JSON", func() {
+				written := writeEvents(event.Log{Payload: "first"}, event.Log{Payload: "second"})
+
+				var decoded map[string]interface{}
+				gt.Expect(json.Unmarshal([]byte(written), &decoded)).To(gomega.Succeed())
+				gt.Expect(decoded["events"]).To(gomega.HaveLen(2))
+			})
+		}, spec.Nested())
+
+		when("there are no events", func() {
+			it("writes an empty array", func() {
+				gt.Expect(writeEvents()).To(gomega.Equal(`{"concourse_build_resource":{"release":"v0.99.11"},"events":[]}` + "\n"))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
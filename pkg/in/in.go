@@ -3,9 +3,13 @@ package in
 import (
 	"encoding/json"
 	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
 	"github.com/concourse/fly/eventstream"
 	"github.com/docker/docker/pkg/fileutils"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/buildevents"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/client"
 	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/timings"
 	"io"
 	"io/ioutil"
 	"log"
@@ -13,9 +17,7 @@ import (
 
 	gc "github.com/concourse/go-concourse/concourse"
 
-	"crypto/tls"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -30,14 +32,34 @@ type versionedResourceTypesWrapper struct {
 	VersionedResourceTypes atc.VersionedResourceTypes `json:"versioned_resource_types"`
 }
 
+// oneOffBuildPlaceholder stands in for the job of a build created with fly execute.
+type oneOffBuildPlaceholder struct {
+	OneOffBuild bool `json:"one_off_build"`
+}
+
 type eventEnvelope struct {
 	Data    atc.Event        `json:"data"`
 	Event   atc.EventType    `json:"event"`
 	Version atc.EventVersion `json:"version"`
 }
 
-type eventsWrapper struct {
-	Events []eventEnvelope `json:"events"`
+// Artifacts which can be listed in params.fetch.
+const (
+	artifactBuild                  = "build"
+	artifactResources              = "resources"
+	artifactPlan                   = "plan"
+	artifactJob                    = "job"
+	artifactVersionedResourceTypes = "versioned_resource_types"
+	artifactEvents                 = "events"
+)
+
+var fetchableArtifacts = []string{
+	artifactBuild,
+	artifactResources,
+	artifactPlan,
+	artifactJob,
+	artifactVersionedResourceTypes,
+	artifactEvents,
 }
 
 type inner struct {
@@ -50,7 +72,6 @@ type inner struct {
 	plan                   atc.PublicBuildPlan
 	job                    atc.Job
 	versionedResourceTypes versionedResourceTypesWrapper
-	events                 eventsWrapper
 	buildId                int
 }
 
@@ -59,92 +80,113 @@ func (i inner) In() (*config.InResponse, error) {
 		log.Printf("Received InRequest: %+v", i.inRequest)
 	}
 
-	err := i.getConcourseInfo()
+	err := i.checkFetchParams()
 	if err != nil {
 		return nil, err
 	}
 
-	err = i.getBuildId()
+	err = i.checkTimestampedLogParam()
 	if err != nil {
 		return nil, err
 	}
 
-	// the build
-	err = i.getBuild()
-	if err != nil {
-		return nil, err
+	// out acting on a pipeline or job which has never run gives a version without a build
+	if i.inRequest.Version.BuildId == "" {
+		log.Printf("version has no build_id, so there is no build to fetch")
+		return &config.InResponse{
+			Version:  i.inRequest.Version,
+			Metadata: []config.VersionMetadataField{},
+		}, nil
 	}
 
-	err = i.writeJsonFile("build", i.build)
+	err = i.getConcourseInfo()
 	if err != nil {
 		return nil, err
 	}
 
-	// resources
-	err = i.getResources()
+	err = i.getBuildId()
 	if err != nil {
 		return nil, err
 	}
 
-	err = i.writeJsonFile("resources", i.resources)
+	// the build
+	err = i.getBuild()
 	if err != nil {
 		return nil, err
 	}
 
-	// plan
-	err = i.getPlan()
-	if err != nil {
-		return nil, err
+	// the team might not have been given in source, so team-scoped fetches use the build's team
+	if i.inRequest.Source.Team == "" {
+		i.concourseTeam = i.concourseClient.Team(i.build.TeamName)
 	}
 
-	err = i.writeJsonFile("plan", i.plan)
+	err = i.writeJsonFile("build", i.build)
 	if err != nil {
 		return nil, err
 	}
 
-	// job
-	err = i.getJob()
-	if err != nil {
-		return nil, err
-	}
+	// resources
+	if i.fetches(artifactResources) {
+		err = i.getResources()
+		if err != nil {
+			return nil, err
+		}
 
-	err = i.writeJsonFile("job", i.job)
-	if err != nil {
-		return nil, err
+		err = i.writeJsonFile("resources", i.resources)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// versioned resource types
-	err = i.getVersionedResourceTypes()
-	if err != nil {
-		return nil, err
-	}
+	// plan
+	if i.fetches(artifactPlan) {
+		err = i.getPlan()
+		if err != nil {
+			return nil, err
+		}
 
-	err = i.writeJsonFile("versioned_resource_types", i.versionedResourceTypes)
-	if err != nil {
-		return nil, err
+		err = i.writeJsonFile("plan", i.plan)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Events need to be fetched twice: once for the .json, once for the .log.
-	// The json follows the same basic logic as other endpoints. The log file however
-	// requires me to use a Concourse package to render it. This has clever EOFing
-	// stuff that I don't feel comfortable trying to reverse engineer, hence the
-	// double-handling of events.
+	// job, which one-off builds don't have
+	if i.fetches(artifactJob) {
+		if i.build.OneOff() {
+			err = i.writeJsonFile("job", oneOffBuildPlaceholder{OneOffBuild: true})
+		} else {
+			err = i.getJob()
+			if err != nil {
+				return nil, err
+			}
 
-	// events part 1: JSON
-	err = i.getEventsForJson()
-	if err != nil {
-		return nil, err
+			err = i.writeJsonFile("job", i.job)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	err = i.writeJsonFile("events", i.events)
-	if err != nil {
-		return nil, err
+	// versioned resource types
+	if i.fetches(artifactVersionedResourceTypes) {
+		err = i.getVersionedResourceTypes()
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.writeJsonFile("versioned_resource_types", i.versionedResourceTypes)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// events part 2: rendering to pretty text
-	err = i.getAndWriteRenderedEventLog()
-	if err != nil {
-		return nil, err
+	// events, rendered into the log and written out as JSON from a single fetch
+	if i.fetches(artifactEvents) {
+		err = i.getAndWriteEvents()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// K-V convenience files
@@ -154,23 +196,69 @@ func (i inner) In() (*config.InResponse, error) {
 	}
 
 	return &config.InResponse{
-		Version: i.inRequest.Version,
-		Metadata: []config.VersionMetadataField{
-			{Name: "build_url", Value: i.buildUrl()},
-		},
+		Version:  i.inRequest.Version,
+		Metadata: i.metadata(),
 	}, nil
 }
 
-func NewInner(input *config.InRequest) Inner {
-	tr := &http.Transport{
-		MaxIdleConns:    10,
-		IdleConnTimeout: 30 * time.Second,
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+// checkFetchParams makes sure that params.fetch only names things which can be fetched.
+func (i *inner) checkFetchParams() error {
+	for _, artifact := range i.inRequest.Params.Fetch {
+		if !isFetchable(artifact) {
+			return fmt.Errorf("unknown artifact '%s' in params.fetch, expected any of '%s'", artifact, strings.Join(fetchableArtifacts, "', '"))
+		}
+	}
+
+	return nil
+}
+
+// checkTimestampedLogParam makes sure that params.timestamped_log is a kind of timestamp, if it is set.
+func (i *inner) checkTimestampedLogParam() error {
+	switch i.inRequest.Params.TimestampedLog {
+	case "", timestampsAbsolute, timestampsRelative:
+		return nil
+	default:
+		return fmt.Errorf("unknown timestamped_log '%s', expected '%s' or '%s'", i.inRequest.Params.TimestampedLog, timestampsAbsolute, timestampsRelative)
+	}
+}
+
+// fetches is whether the params ask for the artifact. The build itself is always fetched, since everything else
+// about the get depends on it.
+func (i *inner) fetches(artifact string) bool {
+	params := i.inRequest.Params
+	if artifact == artifactEvents && params.SkipEvents {
+		return false
+	}
+	if len(params.Fetch) == 0 {
+		return true
+	}
+
+	for _, fetch := range params.Fetch {
+		if fetch == artifact {
+			return true
+		}
 	}
-	client := &http.Client{Transport: tr}
-	concourse := gc.NewClient(input.Source.ConcourseUrl, client, input.Source.EnableTracing)
 
-	return NewInnerUsingClient(input, concourse)
+	return false
+}
+
+func isFetchable(artifact string) bool {
+	for _, fetchable := range fetchableArtifacts {
+		if artifact == fetchable {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewInner(input *config.InRequest) (Inner, error) {
+	concourse, err := client.New(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInnerUsingClient(input, concourse), nil
 }
 
 func NewInnerUsingClient(input *config.InRequest, client gc.Client) Inner {
@@ -246,11 +334,6 @@ func (i *inner) getPlan() error {
 }
 
 func (i *inner) getJob() error {
-	// if the concourse team was blank in source, we need to replace here based on the build response.
-	if i.inRequest.Source.Team == "" {
-		i.concourseTeam = i.concourseClient.Team(i.build.TeamName)
-	}
-
 	// use build information as team, pipeline and job names might not have been provided in source
 	var err error
 	var found bool
@@ -266,6 +349,12 @@ func (i *inner) getJob() error {
 }
 
 func (i *inner) getVersionedResourceTypes() error {
+	// one-off builds which weren't run against a pipeline have no resource types to look up
+	if i.build.PipelineName == "" {
+		i.versionedResourceTypes = versionedResourceTypesWrapper{VersionedResourceTypes: atc.VersionedResourceTypes{}}
+		return nil
+	}
+
 	var err error
 	var found bool
 	verResTypes, found, err := i.concourseTeam.VersionedResourceTypes(i.build.PipelineName)
@@ -281,74 +370,283 @@ func (i *inner) getVersionedResourceTypes() error {
 	return nil
 }
 
-func (i *inner) getEventsForJson() error {
-	eventsForJsonFile, err := i.concourseClient.BuildEvents(i.inRequest.Version.BuildId)
-	if err != nil && err.Error() == "not authorized" {
-		log.Printf("was unauthorized to fetch events for build '%s', no event JSON will be written.", i.inRequest.Version.BuildId)
-		return nil
+// getAndWriteEvents reads the build's events once. As fly renders them into events.log, each one is also written
+// to events.json and to the log of its step, so all the files always describe the same events. None of them are
+// held in memory.
+func (i *inner) getAndWriteEvents() error {
+	buildEvents, authorized, err := buildevents.Open(i.concourseClient, i.inRequest.Version.BuildId)
+	if err != nil {
+		return err
+	}
+	if authorized {
+		buildEvents = buildevents.SoFar(buildEvents, i.build)
+	} else {
+		// tasks expect the event files to be there, so they are written without any events
+		log.Printf("was unauthorized to fetch events for build '%s', event JSON and logs will be written without any events.", i.inRequest.Version.BuildId)
+		buildEvents = noEvents{}
+	}
+	defer buildEvents.Close()
+
+	stepTimings, err := i.newTimingsRecorder()
+	if err != nil {
+		return err
 	}
+	recorders, err := i.openEventRecorders()
+	if err != nil {
+		return err
+	}
+	recorders = append(recorders, stepTimings)
+	events := newRecordedEvents(buildEvents, func(envelope eventEnvelope) error {
+		for _, recorder := range recorders {
+			err := recorder.write(envelope)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	eventLogs, err := i.openEventLogs()
 	if err != nil {
-		return fmt.Errorf("error while fetching events for build '%s': '%s", i.inRequest.Version.BuildId, err.Error())
+		closeEventRecorders(recorders)
+		return err
+	}
+
+	eventstream.Render(eventLogs, events)
+
+	err = events.drain()
+	logsCloseErr := eventLogs.Close()
+	recordersCloseErr := closeEventRecorders(recorders)
+	for _, e := range []error{err, logsCloseErr, recordersCloseErr} {
+		if e != nil {
+			return e
+		}
 	}
-	defer eventsForJsonFile.Close()
 
-	eventsArr := make([]eventEnvelope, 0)
-	for {
-		ev, err := eventsForJsonFile.NextEvent()
+	extensions := append([]string{"json", "html"}, i.eventLogExtensions()...)
+	if i.inRequest.Params.TimestampedLog != "" {
+		extensions = append(extensions, "timestamped.log")
+	}
+	for _, extension := range extensions {
+		err = i.copyToPostfixedFiles(filepath.Join(i.inRequest.WorkingDirectory, "events."+extension), "events", extension)
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
 			return err
 		}
+	}
 
-		var builtEv eventEnvelope
-		builtEv.Data = ev
-		builtEv.Event = ev.EventType()
-		builtEv.Version = ev.Version()
+	return i.writeJsonFile("timings", stepTimings.Timings())
+}
+
+// eventRecorder keeps a record of each event as the events are read for rendering into the event logs.
+type eventRecorder interface {
+	write(envelope eventEnvelope) error
+	close() error
+}
+
+// openEventRecorders opens events.json, events.html, the step logs and, if params ask for it, the timestamped log.
+func (i *inner) openEventRecorders() ([]eventRecorder, error) {
+	var recorders []eventRecorder
 
-		eventsArr = append(eventsArr, builtEv)
+	eventsJson, err := newEventsJsonWriter(filepath.Join(i.inRequest.WorkingDirectory, "events.json"), i.jsonMetadataPrefix())
+	if err != nil {
+		return nil, err
 	}
+	recorders = append(recorders, eventsJson)
 
-	i.events = eventsWrapper{Events: eventsArr}
+	stepLogs, err := i.newStepLogsWriter()
+	if err != nil {
+		closeEventRecorders(recorders)
+		return nil, err
+	}
+	recorders = append(recorders, stepLogs)
+
+	eventsHtml, err := i.newEventsHtmlWriter()
+	if err != nil {
+		closeEventRecorders(recorders)
+		return nil, err
+	}
+	recorders = append(recorders, eventsHtml)
+
+	if i.inRequest.Params.TimestampedLog != "" {
+		timestampedLog, err := i.newTimestampedLogWriter()
+		if err != nil {
+			closeEventRecorders(recorders)
+			return nil, err
+		}
+		recorders = append(recorders, timestampedLog)
+	}
+
+	return recorders, nil
+}
 
+// timingsRecorder works out how long each step took, for timings.json.
+type timingsRecorder struct {
+	*timings.Recorder
+}
+
+func (t timingsRecorder) write(envelope eventEnvelope) error {
+	t.Record(envelope.Data)
 	return nil
 }
 
-func (i *inner) getAndWriteRenderedEventLog() error {
-	eventsForLogFile, err := i.concourseClient.BuildEvents(i.inRequest.Version.BuildId)
-	// first, check if we are even authorised
-	if err != nil && err.Error() == "not authorized" {
-		log.Printf("was unauthorized to fetch events for build '%s', no logs will be written.", i.inRequest.Version.BuildId)
-		return nil
+func (t timingsRecorder) close() error {
+	return nil
+}
+
+// newTimingsRecorder names steps from the plan, if it was fetched.
+func (i *inner) newTimingsRecorder() (timingsRecorder, error) {
+	names := make(map[event.OriginID]timings.StepName)
+	if i.fetches(artifactPlan) {
+		steps, err := planSteps(i.plan)
+		if err != nil {
+			return timingsRecorder{}, err
+		}
+		for id, step := range steps {
+			names[id] = timings.StepName{Kind: step.kind, Name: step.name}
+		}
 	}
-	if err != nil {
-		return fmt.Errorf("error while fetching events for build '%s': '%s", i.inRequest.Version.BuildId, err.Error())
+
+	return timingsRecorder{timings.NewRecorder(names)}, nil
+}
+
+func closeEventRecorders(recorders []eventRecorder) error {
+	var firstErr error
+	for _, recorder := range recorders {
+		err := recorder.close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	defer eventsForLogFile.Close()
 
-	unadornedLogPath := filepath.Join(i.inRequest.WorkingDirectory, "events.log")
-	eventsLogFile, err := os.Create(unadornedLogPath)
-	if err != nil {
-		return err
+	return firstErr
+}
+
+// eventLogExtensions are those of events.log, as rendered by fly, and events.plain.log, which has the terminal
+// colours and progress redraws taken out. Only the plain log is written if params ask for it alone.
+func (i *inner) eventLogExtensions() []string {
+	if i.inRequest.Params.PlainLogOnly {
+		return []string{"plain.log"}
 	}
 
-	eventstream.Render(eventsLogFile, eventsForLogFile)
-	eventsLogFile.Close()
+	return []string{"log", "plain.log"}
+}
 
-	detailedLogPath := filepath.Join(i.inRequest.WorkingDirectory, fmt.Sprintf("%s.log", i.addDetailedPostfixTo("events")))
-	_, err = fileutils.CopyFile(unadornedLogPath, detailedLogPath)
-	if err != nil {
-		return err
+// openEventLogs opens the event logs for rendering into all at once.
+func (i *inner) openEventLogs() (io.WriteCloser, error) {
+	logs := make(multiWriteCloser, 0, 2)
+	for _, extension := range i.eventLogExtensions() {
+		file, err := os.Create(filepath.Join(i.inRequest.WorkingDirectory, "events."+extension))
+		if err != nil {
+			logs.Close()
+			return nil, err
+		}
+
+		if extension == "plain.log" {
+			logs = append(logs, newPlainTextWriter(file))
+		} else {
+			logs = append(logs, file)
+		}
+	}
+
+	return logs, nil
+}
+
+type multiWriteCloser []io.WriteCloser
+
+func (m multiWriteCloser) Write(p []byte) (int, error) {
+	for _, w := range m {
+		_, err := w.Write(p)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (m multiWriteCloser) Close() error {
+	var firstErr error
+	for _, w := range m {
+		err := w.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
 
-	numberedLogPath := filepath.Join(i.inRequest.WorkingDirectory, fmt.Sprintf("%s.log", i.addBuildNumberPostfixTo("events")))
-	_, err = fileutils.CopyFile(unadornedLogPath, numberedLogPath)
+	return firstErr
+}
+
+// newTimestampedLogWriter opens events.timestamped.log, with the kind of timestamps that params ask for.
+func (i *inner) newTimestampedLogWriter() (*timestampedLogWriter, error) {
+	file, err := os.Create(filepath.Join(i.inRequest.WorkingDirectory, "events.timestamped.log"))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return newTimestampedLogWriter(file, i.inRequest.Params.TimestampedLog, i.build.StartTime, i.inRequest.Params.PlainLogOnly), nil
+}
+
+// newEventsHtmlWriter opens events.html. Steps get sections of their own if the plan was fetched, and the inputs
+// and outputs are listed if the resources were.
+func (i *inner) newEventsHtmlWriter() (*eventsHtmlWriter, error) {
+	titles := make(map[event.OriginID]string)
+	if i.fetches(artifactPlan) {
+		steps, err := planSteps(i.plan)
+		if err != nil {
+			return nil, err
+		}
+		for id, step := range steps {
+			titles[id] = fmt.Sprintf("%s: %s", step.kind, step.name)
+		}
+	}
+
+	return newEventsHtmlWriter(filepath.Join(i.inRequest.WorkingDirectory, "events.html"), i.eventsHtmlHeader(), titles)
+}
+
+func (i *inner) eventsHtmlHeader() eventsHtmlHeader {
+	header := eventsHtmlHeader{
+		Title:    fmt.Sprintf("%s/%s/%s #%s", i.build.TeamName, i.build.PipelineName, i.build.JobName, i.build.Name),
+		BuildUrl: i.buildUrl(),
+		Status:   i.build.Status,
+	}
+	if i.build.OneOff() {
+		header.Title = fmt.Sprintf("%s one-off build #%d", i.build.TeamName, i.build.ID)
+	}
+
+	if i.build.StartTime > 0 {
+		header.Started = time.Unix(i.build.StartTime, 0).UTC().Format(time.RFC3339)
+	}
+	if i.build.StartTime > 0 && i.build.EndTime >= i.build.StartTime {
+		header.Ended = time.Unix(i.build.EndTime, 0).UTC().Format(time.RFC3339)
+		header.Duration = (time.Duration(i.build.EndTime-i.build.StartTime) * time.Second).String()
+	}
+
+	if i.fetches(artifactResources) {
+		for _, input := range i.resources.Inputs {
+			header.Inputs = append(header.Inputs, eventsHtmlResource{Name: input.Name, Type: input.Type, Version: versionString(input.Version)})
+		}
+		for _, output := range i.resources.Outputs {
+			header.Outputs = append(header.Outputs, eventsHtmlResource{Name: output.Resource, Type: output.Type, Version: versionString(output.Version)})
+		}
+	}
+
+	return header
+}
+
+// newStepLogsWriter splits the build's output into a log per step under steps/. Steps are named from the plan, so
+// without it nothing is split out.
+func (i *inner) newStepLogsWriter() (*stepLogsWriter, error) {
+	names := make(map[event.OriginID]string)
+	if i.fetches(artifactPlan) {
+		var err error
+		names, err = stepLogNames(i.plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newStepLogsWriter(filepath.Join(i.inRequest.WorkingDirectory, "steps"), names, i.inRequest.Params.PlainLogOnly)
 }
 
 func (i *inner) writeConvenienceKeyValueFiles() error {
@@ -376,6 +674,47 @@ func (i *inner) writeConvenienceKeyValueFiles() error {
 	return nil
 }
 
+// metadata is shown on the get step in the Concourse UI, so that the build can be understood without opening files.
+func (i *inner) metadata() []config.VersionMetadataField {
+	metadata := []config.VersionMetadataField{
+		{Name: "build_url", Value: i.buildUrl()},
+	}
+
+	addIfSet := func(name, value string) {
+		if value != "" {
+			metadata = append(metadata, config.VersionMetadataField{Name: name, Value: value})
+		}
+	}
+
+	addIfSet("team", i.build.TeamName)
+	addIfSet("pipeline", i.build.PipelineName)
+	addIfSet("job", i.build.JobName)
+	addIfSet("job_number", i.build.Name)
+	addIfSet("status", i.build.Status)
+
+	if i.build.StartTime > 0 {
+		addIfSet("started_time", time.Unix(i.build.StartTime, 0).UTC().Format(time.RFC3339))
+	}
+	if i.build.EndTime > 0 {
+		addIfSet("ended_time", time.Unix(i.build.EndTime, 0).UTC().Format(time.RFC3339))
+	}
+	if i.build.StartTime > 0 && i.build.EndTime >= i.build.StartTime {
+		addIfSet("duration", (time.Duration(i.build.EndTime-i.build.StartTime) * time.Second).String())
+	}
+
+	for _, input := range i.resources.Inputs {
+		version, err := json.Marshal(input.Version)
+		if err != nil {
+			continue
+		}
+		addIfSet(fmt.Sprintf("input_%s", input.Name), string(version))
+	}
+
+	addIfSet("concourse_version", i.concourseInfo.Version)
+
+	return metadata
+}
+
 func (i *inner) concourseUrl() string {
 	return i.inRequest.Source.ConcourseUrl
 }
@@ -389,6 +728,10 @@ func (i *inner) teamUrl() string {
 }
 
 func (i *inner) pipelineUrl() string {
+	if i.build.PipelineName == "" {
+		return ""
+	}
+
 	return fmt.Sprintf(
 		"%s/pipelines/%s",
 		i.teamUrl(),
@@ -397,6 +740,10 @@ func (i *inner) pipelineUrl() string {
 }
 
 func (i *inner) jobUrl() string {
+	if i.build.OneOff() {
+		return ""
+	}
+
 	return fmt.Sprintf(
 		"%s/jobs/%s",
 		i.pipelineUrl(),
@@ -405,6 +752,11 @@ func (i *inner) jobUrl() string {
 }
 
 func (i *inner) buildUrl() string {
+	// one-off builds are only shown at the top level of the web UI
+	if i.build.OneOff() {
+		return fmt.Sprintf("%s/builds/%d", i.concourseUrl(), i.build.ID)
+	}
+
 	return fmt.Sprintf(
 		"%s/builds/%s",
 		i.jobUrl(),
@@ -434,16 +786,8 @@ func (i *inner) writeJsonFile(filename string, object interface{}) error {
 		return fmt.Errorf("could not encode response from server into '%s': %s", filename, err.Error())
 	}
 
-	getMetadataStr := fmt.Sprintf(
-		`{"concourse_build_resource":{"release":"%s","git_ref":"%s","get_timestamp":%d,"concourse_version":"%s","get_uuid":"%s"},`,
-		i.inRequest.ReleaseVersion,
-		i.inRequest.ReleaseGitRef,
-		i.inRequest.GetTimestamp,
-		i.concourseInfo.Version,
-		i.inRequest.GetUuid,
-	)
 	jsonStr := builder.String()
-	jsonStr = strings.Replace(jsonStr, "{", getMetadataStr, 1)
+	jsonStr = strings.Replace(jsonStr, "{", i.jsonMetadataPrefix(), 1)
 
 	unadornedJsonPath := filepath.Join(i.inRequest.WorkingDirectory, fmt.Sprintf("%s.json", filename))
 	err = ioutil.WriteFile(unadornedJsonPath, []byte(jsonStr), os.ModePerm)
@@ -451,14 +795,31 @@ func (i *inner) writeJsonFile(filename string, object interface{}) error {
 		return err
 	}
 
-	detailedJsonPath := filepath.Join(i.inRequest.WorkingDirectory, fmt.Sprintf("%s.json", i.addDetailedPostfixTo(filename)))
-	_, err = fileutils.CopyFile(unadornedJsonPath, detailedJsonPath)
+	return i.copyToPostfixedFiles(unadornedJsonPath, filename, "json")
+}
+
+// jsonMetadataPrefix opens a JSON object with the metadata which is injected into every JSON file.
+func (i *inner) jsonMetadataPrefix() string {
+	return fmt.Sprintf(
+		`{"concourse_build_resource":{"release":"%s","git_ref":"%s","get_timestamp":%d,"concourse_version":"%s","get_uuid":"%s"},`,
+		i.inRequest.ReleaseVersion,
+		i.inRequest.ReleaseGitRef,
+		i.inRequest.GetTimestamp,
+		i.concourseInfo.Version,
+		i.inRequest.GetUuid,
+	)
+}
+
+// copyToPostfixedFiles copies a file to the variations with build details and the global build number in its name.
+func (i *inner) copyToPostfixedFiles(unadornedPath, filename, extension string) error {
+	detailedPath := filepath.Join(i.inRequest.WorkingDirectory, fmt.Sprintf("%s.%s", i.addDetailedPostfixTo(filename), extension))
+	_, err := fileutils.CopyFile(unadornedPath, detailedPath)
 	if err != nil {
 		return err
 	}
 
-	numberedJsonPath := filepath.Join(i.inRequest.WorkingDirectory, fmt.Sprintf("%s.json", i.addBuildNumberPostfixTo(filename)))
-	_, err = fileutils.CopyFile(unadornedJsonPath, numberedJsonPath)
+	numberedPath := filepath.Join(i.inRequest.WorkingDirectory, fmt.Sprintf("%s.%s", i.addBuildNumberPostfixTo(filename), extension))
+	_, err = fileutils.CopyFile(unadornedPath, numberedPath)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package in_test
 
 import (
+	"github.com/concourse/go-concourse/concourse"
 	fakes "github.com/concourse/go-concourse/concourse/concoursefakes"
 	"github.com/concourse/go-concourse/concourse/eventstream/eventstreamfakes"
 	"github.com/nu7hatch/gouuid"
@@ -13,7 +14,9 @@ import (
 	"github.com/jchesterpivotal/concourse-build-resource/pkg/in"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
 
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -59,7 +62,9 @@ func TestInPkg(t *testing.T) {
 						APIURL:       "/api/v1/builds/999",
 					}, true, nil)
 					fakeclient.BuildResourcesReturns(atc.BuildInputsOutputs{
-						Inputs:  []atc.PublicBuildInput{},
+						Inputs: []atc.PublicBuildInput{
+							{Name: "source-code", Version: atc.Version{"ref": "abc123"}},
+						},
 						Outputs: []atc.VersionedResource{},
 					}, true, nil)
 					fakeclient.BuildPlanReturns(atc.PublicBuildPlan{}, true, nil)
@@ -97,6 +102,22 @@ func TestInPkg(t *testing.T) {
 					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "build_url", Value: "https://example.com/teams/team/pipelines/pipeline/jobs/job/builds/111"}))
 				})
 
+				it("returns metadata describing the build", func() {
+					gt.Expect(response.Metadata).To(gomega.Equal([]config.VersionMetadataField{
+						{Name: "build_url", Value: "https://example.com/teams/team/pipelines/pipeline/jobs/job/builds/111"},
+						{Name: "team", Value: "team"},
+						{Name: "pipeline", Value: "pipeline"},
+						{Name: "job", Value: "job"},
+						{Name: "job_number", Value: "111"},
+						{Name: "status", Value: "succeeded"},
+						{Name: "started_time", Value: "2002-01-03T23:36:50Z"},
+						{Name: "ended_time", Value: "2007-10-09T08:39:51Z"},
+						{Name: "duration", Value: "50505h3m1s"},
+						{Name: "input_source-code", Value: `{"ref":"abc123"}`},
+						{Name: "concourse_version", Value: "3.99.11"},
+					}))
+				})
+
 				it("writes out the build.json file", func() {
 					gt.Expect(AFileExistsContaining("build/build.json", `"api_url":"/api/v1/builds/999"`, gt)).To(gomega.BeTrue())
 				})
@@ -345,7 +366,9 @@ func TestInPkg(t *testing.T) {
 			when("only the concourse URL was specified", func() {
 				it.Before(func() {
 					fakeclient.BuildReturns(atc.Build{
-						TeamName: "team-from-build",
+						TeamName:     "team-from-build",
+						PipelineName: "pipeline-from-build",
+						JobName:      "job-from-build",
 					}, true, nil)
 					fakeclient.BuildResourcesReturns(atc.BuildInputsOutputs{}, true, nil)
 					fakeclient.BuildPlanReturns(atc.PublicBuildPlan{}, true, nil)
@@ -372,6 +395,448 @@ func TestInPkg(t *testing.T) {
 				})
 			}, spec.Nested())
 
+			when("only the concourse URL was specified and fetch leaves out the job", func() {
+				teamlessTeam := new(fakes.FakeTeam)
+				buildTeam := new(fakes.FakeTeam)
+				teamlessClient := new(fakes.FakeClient)
+
+				it.Before(func() {
+					teamlessClient.TeamStub = func(name string) concourse.Team {
+						if name == "team-from-build" {
+							return buildTeam
+						}
+						return teamlessTeam
+					}
+					teamlessClient.GetInfoReturns(atc.Info{Version: "3.99.11"}, nil)
+					teamlessClient.BuildReturns(atc.Build{
+						ID:           999,
+						Name:         "111",
+						TeamName:     "team-from-build",
+						PipelineName: "pipeline-from-build",
+						JobName:      "job-from-build",
+						Status:       "succeeded",
+					}, true, nil)
+					teamlessTeam.VersionedResourceTypesReturns(nil, false, nil)
+					buildTeam.VersionedResourceTypesReturns(atc.VersionedResourceTypes{{ResourceType: atc.ResourceType{CheckEvery: "10m"}}}, true, nil)
+
+					inner := in.NewInnerUsingClient(&config.InRequest{
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "",
+						},
+						Version:          config.Version{BuildId: "999"},
+						Params:           config.InParams{Fetch: []string{"build", "versioned_resource_types"}},
+						WorkingDirectory: "build",
+					}, teamlessClient)
+					response, err = inner.In()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("uses the build's team name to fetch the versioned resource types", func() {
+					gt.Expect(buildTeam.VersionedResourceTypesCallCount()).To(gomega.Equal(1))
+					gt.Expect(buildTeam.VersionedResourceTypesArgsForCall(0)).To(gomega.Equal("pipeline-from-build"))
+					gt.Expect(teamlessTeam.VersionedResourceTypesCallCount()).To(gomega.BeZero())
+				})
+			}, spec.Nested())
+
+			when("the version has the fields which describe the build", func() {
+				versionedTeam := new(fakes.FakeTeam)
+				versionedClient := new(fakes.FakeClient)
+				versionedClient.TeamReturns(versionedTeam)
+				version := config.Version{
+					BuildId:   "999",
+					Team:      "team",
+					Pipeline:  "pipeline",
+					Job:       "job",
+					JobNumber: "111",
+					Status:    "succeeded",
+				}
+
+				it.Before(func() {
+					versionedClient.GetInfoReturns(atc.Info{Version: "3.99.11"}, nil)
+					versionedClient.BuildReturns(atc.Build{
+						ID:           999,
+						Name:         "111",
+						TeamName:     "team",
+						PipelineName: "pipeline",
+						JobName:      "job",
+						Status:       "succeeded",
+					}, true, nil)
+					versionedClient.BuildResourcesReturns(atc.BuildInputsOutputs{}, true, nil)
+					versionedClient.BuildPlanReturns(atc.PublicBuildPlan{}, true, nil)
+					versionedTeam.JobReturns(atc.Job{}, true, nil)
+					versionedTeam.VersionedResourceTypesReturns(atc.VersionedResourceTypes{}, true, nil)
+					fakeeventstream.NextEventReturns(nil, io.EOF)
+					versionedClient.BuildEventsReturns(fakeeventstream, nil)
+
+					inner := in.NewInnerUsingClient(&config.InRequest{
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "team",
+							Pipeline:     "pipeline",
+							Job:          "job",
+						},
+						Version:          version,
+						Params:           config.InParams{},
+						WorkingDirectory: "build",
+					}, versionedClient)
+					response, err = inner.In()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("fetches the build by its build_id", func() {
+					gt.Expect(versionedClient.BuildArgsForCall(0)).To(gomega.Equal("999"))
+				})
+
+				it("returns the whole version it was given", func() {
+					gt.Expect(response.Version).To(gomega.Equal(version))
+				})
+			}, spec.Nested())
+
+			when("the version has no build_id", func() {
+				buildlessClient := new(fakes.FakeClient)
+				version := config.Version{Team: "team", Pipeline: "pipeline", Job: "job"}
+
+				it.Before(func() {
+					inner := in.NewInnerUsingClient(&config.InRequest{
+						Source:           config.Source{ConcourseUrl: "https://example.com", Team: "team"},
+						Version:          version,
+						Params:           config.InParams{},
+						WorkingDirectory: "build",
+					}, buildlessClient)
+					response, err = inner.In()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("does not fetch anything", func() {
+					gt.Expect(buildlessClient.BuildCallCount()).To(gomega.BeZero())
+				})
+
+				it("returns the version it was given", func() {
+					gt.Expect(response.Version).To(gomega.Equal(version))
+				})
+			}, spec.Nested())
+
+			when("the build is a one-off build", func() {
+				oneOffTeam := new(fakes.FakeTeam)
+				oneOffClient := new(fakes.FakeClient)
+				oneOffClient.TeamReturns(oneOffTeam)
+
+				it.Before(func() {
+					os.Remove("build/job.json")
+					os.Remove("build/versioned_resource_types.json")
+
+					oneOffClient.GetInfoReturns(atc.Info{Version: "3.99.11"}, nil)
+					oneOffClient.BuildReturns(atc.Build{
+						ID:       999,
+						Name:     "999",
+						TeamName: "team",
+						Status:   "succeeded",
+					}, true, nil)
+					oneOffClient.BuildResourcesReturns(atc.BuildInputsOutputs{}, true, nil)
+					oneOffClient.BuildPlanReturns(atc.PublicBuildPlan{}, true, nil)
+					fakeeventstream.NextEventReturns(nil, io.EOF)
+					oneOffClient.BuildEventsReturns(fakeeventstream, nil)
+
+					inner := in.NewInnerUsingClient(&config.InRequest{
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "team",
+						},
+						Version:          config.Version{BuildId: "999"},
+						Params:           config.InParams{},
+						WorkingDirectory: "build",
+					}, oneOffClient)
+					response, err = inner.In()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("does not try to fetch the job or versioned resource types", func() {
+					gt.Expect(oneOffTeam.JobCallCount()).To(gomega.BeZero())
+					gt.Expect(oneOffTeam.VersionedResourceTypesCallCount()).To(gomega.BeZero())
+				})
+
+				it("writes a placeholder job.json file", func() {
+					gt.Expect(AFileExistsContaining("build/job.json", `"one_off_build":true`, gt)).To(gomega.BeTrue())
+				})
+
+				it("writes an empty versioned_resource_types.json file", func() {
+					gt.Expect(AFileExistsContaining("build/versioned_resource_types.json", `"versioned_resource_types":[]`, gt)).To(gomega.BeTrue())
+				})
+
+				it("returns the one-off build URL", func() {
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "build_url", Value: "https://example.com/builds/999"}))
+				})
+			}, spec.Nested())
+
+			when("the build has events", func() {
+				eventsClient := new(fakes.FakeClient)
+				eventsTeam := new(fakes.FakeTeam)
+				eventsClient.TeamReturns(eventsTeam)
+				eventsStream := &eventstreamfakes.FakeEventStream{}
+
+				it.Before(func() {
+					eventsClient.GetInfoReturns(atc.Info{Version: "3.99.11"}, nil)
+					eventsClient.BuildReturns(atc.Build{
+						ID:           999,
+						Name:         "111",
+						TeamName:     "team",
+						PipelineName: "pipeline",
+						JobName:      "job",
+						Status:       "succeeded",
+					}, true, nil)
+					eventsClient.BuildResourcesReturns(atc.BuildInputsOutputs{}, true, nil)
+					plan := json.RawMessage(`{"id":"do","do":[{"id":"g1","get":{"type":"git","name":"source-code","resource":"source-code"}},{"id":"t1","task":{"name":"unit","privileged":false}}]}`)
+					eventsClient.BuildPlanReturns(atc.PublicBuildPlan{Schema: "exec.v2", Plan: &plan}, true, nil)
+					eventsTeam.JobReturns(atc.Job{}, true, nil)
+					eventsTeam.VersionedResourceTypesReturns(atc.VersionedResourceTypes{}, true, nil)
+					eventsStream.NextEventReturnsOnCall(0, event.Log{Origin: event.Origin{ID: "t1"}, Time: 1535803201, Payload: "hello from the build\n"}, nil)
+					eventsStream.NextEventReturnsOnCall(1, event.Status{Status: atc.StatusSucceeded}, nil)
+					eventsStream.NextEventReturnsOnCall(2, event.Log{Payload: "after the status\n"}, nil)
+					eventsStream.NextEventReturns(nil, io.EOF)
+					eventsClient.BuildEventsReturns(eventsStream, nil)
+
+					inner := in.NewInnerUsingClient(&config.InRequest{
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "team",
+						},
+						Version:          config.Version{BuildId: "999"},
+						WorkingDirectory: "build",
+					}, eventsClient)
+					response, err = inner.In()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("fetches the events only once", func() {
+					gt.Expect(eventsClient.BuildEventsCallCount()).To(gomega.Equal(1))
+					gt.Expect(eventsStream.CloseCallCount()).To(gomega.Equal(1))
+				})
+
+				it("renders the events into events.log", func() {
+					gt.Expect(AFileExistsContaining("build/events.log", "hello from the build", gt)).To(gomega.BeTrue())
+				})
+
+				it("writes every event into events.json, including those after the renderer stops", func() {
+					gt.Expect(AFileExistsContaining("build/events.json", `{"data":{"time":1535803201,"origin":{"id":"t1"},"payload":"hello from the build\n"},"event":"log","version":"5.1"}`, gt)).To(gomega.BeTrue())
+					gt.Expect(AFileExistsContaining("build/events.json", `"payload":"after the status\n"`, gt)).To(gomega.BeTrue())
+				})
+
+				it("writes out events.plain.log without colour codes", func() {
+					gt.Expect(AFileExistsContaining("build/events.plain.log", "hello from the build\nsucceeded\n", gt)).To(gomega.BeTrue())
+					gt.Expect(AFileExistsContaining("build/events_999.plain.log", "hello from the build", gt)).To(gomega.BeTrue())
+					gt.Expect(AFileExistsContaining("build/events_team_pipeline_job_111.plain.log", "hello from the build", gt)).To(gomega.BeTrue())
+				})
+
+				it("writes out events.html with a section for each step", func() {
+					gt.Expect(AFileExistsContaining("build/events.html", `<a href="https://example.com/teams/team/pipelines/pipeline/jobs/job/builds/111">team/pipeline/job #111</a>`, gt)).To(gomega.BeTrue())
+					gt.Expect(AFileExistsContaining("build/events.html", `<details open><summary>task: unit</summary><pre class="log">hello from the build`, gt)).To(gomega.BeTrue())
+					gt.Expect(AFileExistsContaining("build/events_team_pipeline_job_111.html", "hello from the build", gt)).To(gomega.BeTrue())
+				})
+
+				it("writes out timings.json with the steps which ran", func() {
+					gt.Expect(AFileExistsContaining("build/timings.json", `{"concourse_build_resource":`, gt)).To(gomega.BeTrue())
+					gt.Expect(AFileExistsContaining("build/timings.json", `"steps":[{"id":"t1","kind":"task","name":"unit"`, gt)).To(gomega.BeTrue())
+					gt.Expect("build/timings_999.json").To(gomega.BeAnExistingFile())
+				})
+
+				it("writes a log for each step in the plan", func() {
+					gt.Expect(AFileExistsContaining("build/steps/task_unit.log", "hello from the build", gt)).To(gomega.BeTrue())
+					gt.Expect("build/steps/get_source-code.log").To(gomega.BeAnExistingFile())
+				})
+			}, spec.Nested())
+
+			when("the events are not authorized", func() {
+				unauthorizedTeam := new(fakes.FakeTeam)
+				unauthorizedClient := new(fakes.FakeClient)
+				unauthorizedClient.TeamReturns(unauthorizedTeam)
+
+				it.Before(func() {
+					os.Remove("build/events.json")
+					os.Remove("build/events.log")
+					os.Remove("build/events.plain.log")
+					os.Remove("build/events.html")
+					os.Remove("build/timings.json")
+
+					unauthorizedClient.GetInfoReturns(atc.Info{Version: "3.99.11"}, nil)
+					unauthorizedClient.BuildReturns(atc.Build{
+						ID:           999,
+						Name:         "111",
+						TeamName:     "team",
+						PipelineName: "pipeline",
+						JobName:      "job",
+						Status:       "succeeded",
+					}, true, nil)
+					unauthorizedClient.BuildResourcesReturns(atc.BuildInputsOutputs{}, true, nil)
+					unauthorizedClient.BuildPlanReturns(atc.PublicBuildPlan{}, true, nil)
+					unauthorizedTeam.JobReturns(atc.Job{}, true, nil)
+					unauthorizedTeam.VersionedResourceTypesReturns(atc.VersionedResourceTypes{}, true, nil)
+					unauthorizedClient.BuildEventsReturns(nil, fmt.Errorf("not authorized"))
+
+					inner := in.NewInnerUsingClient(&config.InRequest{
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "team",
+						},
+						Version:          config.Version{BuildId: "999"},
+						WorkingDirectory: "build",
+					}, unauthorizedClient)
+					response, err = inner.In()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("writes out events.json with no events", func() {
+					gt.Expect(AFileExistsContaining("build/events.json", `"events":[]}`, gt)).To(gomega.BeTrue())
+				})
+
+				it("writes out the other event files, empty of events", func() {
+					gt.Expect("build/events.log").To(gomega.BeAnExistingFile())
+					gt.Expect("build/events.plain.log").To(gomega.BeAnExistingFile())
+					gt.Expect("build/events.html").To(gomega.BeAnExistingFile())
+					gt.Expect(AFileExistsContaining("build/timings.json", `"steps":[]`, gt)).To(gomega.BeTrue())
+				})
+			}, spec.Nested())
+
+			when("params say what to fetch", func() {
+				selectiveTeam := new(fakes.FakeTeam)
+				selectiveClient := new(fakes.FakeClient)
+				selectiveClient.TeamReturns(selectiveTeam)
+				var params config.InParams
+
+				it.Before(func() {
+					os.Remove("build/plan.json")
+					os.Remove("build/job.json")
+					os.Remove("build/events.json")
+					os.Remove("build/events.log")
+					os.Remove("build/events.plain.log")
+					os.Remove("build/events.timestamped.log")
+
+					selectiveClient.GetInfoReturns(atc.Info{Version: "3.99.11"}, nil)
+					selectiveClient.BuildReturns(atc.Build{
+						ID:           999,
+						Name:         "111",
+						TeamName:     "team",
+						PipelineName: "pipeline",
+						JobName:      "job",
+						Status:       "succeeded",
+					}, true, nil)
+					selectiveClient.BuildResourcesReturns(atc.BuildInputsOutputs{}, true, nil)
+					selectiveClient.BuildPlanReturns(atc.PublicBuildPlan{}, true, nil)
+					selectiveTeam.JobReturns(atc.Job{}, true, nil)
+					selectiveTeam.VersionedResourceTypesReturns(atc.VersionedResourceTypes{}, true, nil)
+					fakeeventstream.NextEventReturns(nil, io.EOF)
+					selectiveClient.BuildEventsReturns(fakeeventstream, nil)
+				})
+
+				fetchWith := func() {
+					inner := in.NewInnerUsingClient(&config.InRequest{
+						Source: config.Source{
+							ConcourseUrl: "https://example.com",
+							Team:         "team",
+						},
+						Version:          config.Version{BuildId: "999"},
+						Params:           params,
+						WorkingDirectory: "build",
+					}, selectiveClient)
+					response, err = inner.In()
+				}
+
+				when("fetch lists some of the artifacts", func() {
+					it.Before(func() {
+						params = config.InParams{Fetch: []string{"build", "resources"}}
+						fetchWith()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("fetches only those", func() {
+						gt.Expect(selectiveClient.BuildResourcesCallCount()).To(gomega.Equal(1))
+						gt.Expect(selectiveClient.BuildPlanCallCount()).To(gomega.BeZero())
+						gt.Expect(selectiveTeam.JobCallCount()).To(gomega.BeZero())
+						gt.Expect(selectiveTeam.VersionedResourceTypesCallCount()).To(gomega.BeZero())
+						gt.Expect(selectiveClient.BuildEventsCallCount()).To(gomega.BeZero())
+					})
+
+					it("writes out only their files", func() {
+						gt.Expect("build/resources.json").To(gomega.BeAnExistingFile())
+						gt.Expect("build/plan.json").NotTo(gomega.BeAnExistingFile())
+						gt.Expect("build/job.json").NotTo(gomega.BeAnExistingFile())
+						gt.Expect("build/events.json").NotTo(gomega.BeAnExistingFile())
+					})
+
+					it("still writes out the build and its single-value files", func() {
+						gt.Expect(AFileExistsContaining("build/build.json", `"id":999`, gt)).To(gomega.BeTrue())
+						gt.Expect(AFileExistsContaining("build/status", "succeeded", gt)).To(gomega.BeTrue())
+					})
+				}, spec.Nested())
+
+				when("skip_events is set", func() {
+					it.Before(func() {
+						params = config.InParams{SkipEvents: true}
+						fetchWith()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("fetches everything but the events", func() {
+						gt.Expect(selectiveClient.BuildPlanCallCount()).To(gomega.Equal(1))
+						gt.Expect(selectiveTeam.JobCallCount()).To(gomega.Equal(1))
+						gt.Expect(selectiveClient.BuildEventsCallCount()).To(gomega.BeZero())
+						gt.Expect("build/events.json").NotTo(gomega.BeAnExistingFile())
+						gt.Expect("build/events.log").NotTo(gomega.BeAnExistingFile())
+					})
+				}, spec.Nested())
+
+				when("plain_log_only is set", func() {
+					it.Before(func() {
+						params = config.InParams{PlainLogOnly: true}
+						fetchWith()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("writes out events.plain.log but not events.log", func() {
+						gt.Expect("build/events.plain.log").To(gomega.BeAnExistingFile())
+						gt.Expect("build/events.log").NotTo(gomega.BeAnExistingFile())
+					})
+				}, spec.Nested())
+
+				when("timestamped_log is set", func() {
+					it.Before(func() {
+						params = config.InParams{TimestampedLog: "relative"}
+						fetchWith()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("writes out events.timestamped.log as well as the other logs", func() {
+						gt.Expect("build/events.timestamped.log").To(gomega.BeAnExistingFile())
+						gt.Expect("build/events_999.timestamped.log").To(gomega.BeAnExistingFile())
+						gt.Expect("build/events.log").To(gomega.BeAnExistingFile())
+					})
+				}, spec.Nested())
+
+				when("timestamped_log is not a kind of timestamp", func() {
+					it.Before(func() {
+						params = config.InParams{TimestampedLog: "yes"}
+						fetchWith()
+					})
+
+					it("returns an error before fetching anything", func() {
+						gt.Expect(err).To(gomega.MatchError("unknown timestamped_log 'yes', expected 'absolute' or 'relative'"))
+						gt.Expect(selectiveClient.BuildCallCount()).To(gomega.BeZero())
+					})
+				}, spec.Nested())
+
+				when("fetch lists something unknown", func() {
+					it.Before(func() {
+						params = config.InParams{Fetch: []string{"build", "logs"}}
+						fetchWith()
+					})
+
+					it("returns an error before fetching anything", func() {
+						gt.Expect(err).To(gomega.MatchError("unknown artifact 'logs' in params.fetch, expected any of 'build', 'resources', 'plan', 'job', 'versioned_resource_types', 'events'"))
+						gt.Expect(selectiveClient.BuildCallCount()).To(gomega.BeZero())
+					})
+				}, spec.Nested())
+			}, spec.Nested())
+
 			when("the concourse URL has a trailing slash", func() {
 				it.Before(func() {
 					fakeclient.BuildReturns(atc.Build{
@@ -0,0 +1,66 @@
+package in
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+)
+
+// ansiEscapes matches terminal control sequences: colours and cursor movement (CSI), window titles and links (OSC),
+// character set selection, and the remaining two-byte escapes.
+var ansiEscapes = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()*+][0-~]|\x1b[@-Z\\-_]`)
+
+// plainTextWriter removes ANSI escape sequences from what is written through it, and keeps only the last redraw of
+// lines which use carriage returns to show progress. It works a line at a time, so it needs to be closed to write
+// out a final line which doesn't end in a newline.
+type plainTextWriter struct {
+	dst  io.WriteCloser
+	line []byte
+}
+
+func newPlainTextWriter(dst io.WriteCloser) *plainTextWriter {
+	return &plainTextWriter{dst: dst}
+}
+
+func (w *plainTextWriter) Write(p []byte) (int, error) {
+	remaining := p
+	for {
+		newline := bytes.IndexByte(remaining, '\n')
+		if newline < 0 {
+			w.line = append(w.line, remaining...)
+			return len(p), nil
+		}
+
+		w.line = append(w.line, remaining[:newline]...)
+		_, err := w.dst.Write(append(plainLine(w.line), '\n'))
+		if err != nil {
+			return 0, err
+		}
+
+		w.line = w.line[:0]
+		remaining = remaining[newline+1:]
+	}
+}
+
+func (w *plainTextWriter) Close() error {
+	if len(w.line) > 0 {
+		_, err := w.dst.Write(plainLine(w.line))
+		if err != nil {
+			w.dst.Close()
+			return err
+		}
+	}
+
+	return w.dst.Close()
+}
+
+func plainLine(line []byte) []byte {
+	plain := ansiEscapes.ReplaceAll(bytes.TrimRight(line, "\r"), nil)
+
+	// a terminal would have drawn over everything before the last carriage return
+	if redraw := bytes.LastIndexByte(plain, '\r'); redraw >= 0 {
+		plain = plain[redraw+1:]
+	}
+
+	return plain
+}
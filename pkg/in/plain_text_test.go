@@ -0,0 +1,67 @@
+package in
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"bytes"
+)
+
+type closableBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closableBuffer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestPlainTextWriter(t *testing.T) {
+	spec.Run(t, "plainTextWriter", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var dst *closableBuffer
+		var w *plainTextWriter
+
+		it.Before(func() {
+			dst = &closableBuffer{}
+			w = newPlainTextWriter(dst)
+		})
+
+		plain := func(writes ...string) string {
+			for _, write := range writes {
+				_, err := w.Write([]byte(write))
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+			gt.Expect(w.Close()).To(gomega.Succeed())
+			return dst.String()
+		}
+
+		it("removes colours", func() {
+			gt.Expect(plain("\x1b[1mrunning make\x1b[0m\n\x1b[32;1msucceeded\x1b[0m\n")).To(gomega.Equal("running make\nsucceeded\n"))
+		})
+
+		it("removes cursor movement, window titles and other escapes", func() {
+			gt.Expect(plain("\x1b[2K\x1b[1Gdone\x1b]0;title\x07 \x1b(Bok\n")).To(gomega.Equal("done ok\n"))
+		})
+
+		it("keeps only the last redraw of a progress line", func() {
+			gt.Expect(plain("downloading  10%\rdownloading  50%\rdownloading 100%\nnext\n")).To(gomega.Equal("downloading 100%\nnext\n"))
+		})
+
+		it("treats CRLF as a line ending", func() {
+			gt.Expect(plain("windows line\r\n")).To(gomega.Equal("windows line\n"))
+		})
+
+		it("handles escapes and redraws split across writes", func() {
+			gt.Expect(plain("\x1b[3", "1mred\x1b[0m 1", "0%\r", "red 100%", "\n")).To(gomega.Equal("red 100%\n"))
+		})
+
+		it("writes out a final line without a newline when closed", func() {
+			gt.Expect(plain("first\nlast")).To(gomega.Equal("first\nlast"))
+			gt.Expect(dst.closed).To(gomega.BeTrue())
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,76 @@
+package in
+
+import (
+	"github.com/concourse/atc"
+	gc "github.com/concourse/go-concourse/concourse"
+
+	"io"
+)
+
+// recordedEvents hands every event read from a stream to a recorder, so that the same events can be rendered into
+// the log and written out as JSON without fetching them twice.
+type recordedEvents struct {
+	events gc.Events
+	record func(eventEnvelope) error
+	err    error
+}
+
+func newRecordedEvents(events gc.Events, record func(eventEnvelope) error) *recordedEvents {
+	return &recordedEvents{
+		events: events,
+		record: record,
+	}
+}
+
+func (r *recordedEvents) NextEvent() (atc.Event, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	ev, err := r.events.NextEvent()
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+
+	err = r.record(eventEnvelope{
+		Data:    ev,
+		Event:   ev.EventType(),
+		Version: ev.Version(),
+	})
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+
+	return ev, nil
+}
+
+// drain reads whatever is left after a reader stopped early, such as the renderer does once it sees the build's
+// final status. It returns the error which ended the stream, if it wasn't the end of the stream.
+func (r *recordedEvents) drain() error {
+	for {
+		_, err := r.NextEvent()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (r *recordedEvents) Close() error {
+	return r.events.Close()
+}
+
+// noEvents is an event stream which ends straight away, for builds whose events can't be fetched.
+type noEvents struct{}
+
+func (noEvents) NextEvent() (atc.Event, error) {
+	return nil, io.EOF
+}
+
+func (noEvents) Close() error {
+	return nil
+}
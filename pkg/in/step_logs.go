@@ -0,0 +1,215 @@
+package in
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// publicPlan is the shape of plan.json, as far as is needed to find the steps in it.
+type publicPlan struct {
+	ID string `json:"id"`
+
+	Get          *planStep `json:"get,omitempty"`
+	Put          *planStep `json:"put,omitempty"`
+	DependentGet *planStep `json:"dependent_get,omitempty"`
+	Task         *planStep `json:"task,omitempty"`
+
+	Aggregate []publicPlan `json:"aggregate,omitempty"`
+	Do        []publicPlan `json:"do,omitempty"`
+	Retry     []publicPlan `json:"retry,omitempty"`
+
+	OnAbort   *hookedPlan `json:"on_abort,omitempty"`
+	Ensure    *hookedPlan `json:"ensure,omitempty"`
+	OnSuccess *hookedPlan `json:"on_success,omitempty"`
+	OnFailure *hookedPlan `json:"on_failure,omitempty"`
+	Try       *hookedPlan `json:"try,omitempty"`
+	Timeout   *hookedPlan `json:"timeout,omitempty"`
+}
+
+type planStep struct {
+	Name string `json:"name"`
+}
+
+// hookedPlan is a step with a hook, or a step wrapped in try or timeout, which have no hook.
+type hookedPlan struct {
+	Step      *publicPlan `json:"step"`
+	OnAbort   *publicPlan `json:"on_abort,omitempty"`
+	Ensure    *publicPlan `json:"ensure,omitempty"`
+	OnSuccess *publicPlan `json:"on_success,omitempty"`
+	OnFailure *publicPlan `json:"on_failure,omitempty"`
+}
+
+// stepLogNames maps the ID of every get, put and task in the plan to the name of its log file. The get which
+// follows a put shares the put's log, as it does in the web UI.
+func stepLogNames(plan atc.PublicBuildPlan) (map[event.OriginID]string, error) {
+	steps, err := planSteps(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[event.OriginID]string)
+	for id, step := range steps {
+		names[id] = stepLogName(step.kind, step.name)
+	}
+
+	return names, nil
+}
+
+// namedStep is a get, put or task in the plan, by its kind and name. The get which follows a put is counted as
+// part of the put.
+type namedStep struct {
+	kind string
+	name string
+}
+
+// planSteps finds every get, put and task in the plan, however deeply it is nested, by ID.
+func planSteps(plan atc.PublicBuildPlan) (map[event.OriginID]namedStep, error) {
+	steps := make(map[event.OriginID]namedStep)
+	if plan.Plan == nil {
+		return steps, nil
+	}
+
+	var root publicPlan
+	err := json.Unmarshal(*plan.Plan, &root)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the plan to find its steps: %s", err.Error())
+	}
+
+	var visit func(p *publicPlan)
+	visit = func(p *publicPlan) {
+		if p == nil {
+			return
+		}
+
+		switch {
+		case p.Get != nil:
+			steps[event.OriginID(p.ID)] = namedStep{"get", p.Get.Name}
+		case p.Put != nil:
+			steps[event.OriginID(p.ID)] = namedStep{"put", p.Put.Name}
+		case p.DependentGet != nil:
+			steps[event.OriginID(p.ID)] = namedStep{"put", p.DependentGet.Name}
+		case p.Task != nil:
+			steps[event.OriginID(p.ID)] = namedStep{"task", p.Task.Name}
+		}
+
+		for _, children := range [][]publicPlan{p.Aggregate, p.Do, p.Retry} {
+			for i := range children {
+				visit(&children[i])
+			}
+		}
+
+		for _, hooked := range []*hookedPlan{p.OnAbort, p.Ensure, p.OnSuccess, p.OnFailure, p.Try, p.Timeout} {
+			if hooked == nil {
+				continue
+			}
+			visit(hooked.Step)
+			visit(hooked.OnAbort)
+			visit(hooked.Ensure)
+			visit(hooked.OnSuccess)
+			visit(hooked.OnFailure)
+		}
+	}
+	visit(&root)
+
+	return steps, nil
+}
+
+// stepLogName is like task_run-tests.log. Step names can be anything, so path separators are replaced.
+func stepLogName(kind, name string) string {
+	name = strings.NewReplacer("/", "_", `\`, "_").Replace(name)
+	return fmt.Sprintf("%s_%s.log", kind, name)
+}
+
+// stepLogsWriter writes the output of each step into its own file under steps/, in the same way that fly renders
+// the whole build, or as plain text. Steps which run more than once, such as retried ones, have all their attempts
+// in one file.
+type stepLogsWriter struct {
+	names map[event.OriginID]string
+	files map[string]io.WriteCloser
+}
+
+func newStepLogsWriter(dir string, names map[event.OriginID]string, plain bool) (*stepLogsWriter, error) {
+	err := os.MkdirAll(dir, os.ModeDir|os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &stepLogsWriter{
+		names: names,
+		files: make(map[string]io.WriteCloser),
+	}
+
+	// every step gets a file, even if it had no output, so that tasks can rely on it being there
+	for _, name := range names {
+		if _, ok := w.files[name]; ok {
+			continue
+		}
+
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			w.close()
+			return nil, err
+		}
+		if plain {
+			w.files[name] = newPlainTextWriter(file)
+		} else {
+			w.files[name] = file
+		}
+	}
+
+	return w, nil
+}
+
+// stepOutput is the text which fly shows for an event from a step, and the step it came from. Events which fly
+// shows no text for, or which don't come from a step, aren't output.
+func stepOutput(data atc.Event) (origin event.Origin, text string, output bool) {
+	switch e := data.(type) {
+	case event.Log:
+		return e.Origin, e.Payload, true
+	case event.LogV50:
+		return e.Origin, e.Payload, true
+	case event.InitializeTask:
+		return e.Origin, "\x1b[1minitializing\x1b[0m\n", true
+	case event.StartTask:
+		argv := strings.Join(append([]string{e.TaskConfig.Run.Path}, e.TaskConfig.Run.Args...), " ")
+		return e.Origin, fmt.Sprintf("\x1b[1mrunning %s\x1b[0m\n", argv), true
+	case event.Error:
+		return e.Origin, e.Message + "\n", true
+	default:
+		return event.Origin{}, "", false
+	}
+}
+
+func (w *stepLogsWriter) write(envelope eventEnvelope) error {
+	origin, text, output := stepOutput(envelope.Data)
+	if !output {
+		return nil
+	}
+
+	name, ok := w.names[origin.ID]
+	if !ok {
+		return nil
+	}
+
+	_, err := io.WriteString(w.files[name], text)
+	return err
+}
+
+func (w *stepLogsWriter) close() error {
+	var firstErr error
+	for _, file := range w.files {
+		err := file.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
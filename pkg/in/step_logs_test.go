@@ -0,0 +1,131 @@
+package in
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func TestStepLogs(t *testing.T) {
+	spec.Run(t, "step logs", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+
+		when("naming steps from the plan", func() {
+			var names map[event.OriginID]string
+
+			it.Before(func() {
+				plan := json.RawMessage(`{"id":"root","do":[
+					{"id":"agg","aggregate":[
+						{"id":"g1","get":{"type":"git","name":"source-code","resource":"source-code"}},
+						{"id":"g2","get":{"type":"time","name":"nightly","resource":"nightly"}}
+					]},
+					{"id":"hook","on_failure":{
+						"step":{"id":"try","try":{"step":{"id":"t1","task":{"name":"unit","privileged":false}}}},
+						"on_failure":{"id":"t2","task":{"name":"notify/slack","privileged":false}}
+					}},
+					{"id":"retry","retry":[
+						{"id":"t3a","task":{"name":"flaky","privileged":false}},
+						{"id":"t3b","task":{"name":"flaky","privileged":false}}
+					]},
+					{"id":"ensure","ensure":{
+						"step":{"id":"p1","put":{"type":"s3","name":"release","resource":"release"}},
+						"ensure":{"id":"dg1","dependent_get":{"type":"s3","name":"release","resource":"release"}}
+					}}
+				]}`)
+
+				var err error
+				names, err = stepLogNames(atc.PublicBuildPlan{Schema: "exec.v2", Plan: &plan})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("finds every get, put and task however deeply they are nested", func() {
+				gt.Expect(names).To(gomega.Equal(map[event.OriginID]string{
+					"g1":  "get_source-code.log",
+					"g2":  "get_nightly.log",
+					"t1":  "task_unit.log",
+					"t2":  "task_notify_slack.log",
+					"t3a": "task_flaky.log",
+					"t3b": "task_flaky.log",
+					"p1":  "put_release.log",
+					"dg1": "put_release.log",
+				}))
+			})
+		}, spec.Nested())
+
+		when("there is no plan", func() {
+			it("has no steps", func() {
+				names, err := stepLogNames(atc.PublicBuildPlan{})
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				gt.Expect(names).To(gomega.BeEmpty())
+			})
+		}, spec.Nested())
+
+		when("writing step logs", func() {
+			var dir string
+
+			it.Before(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "steps")
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				w, err := newStepLogsWriter(filepath.Join(dir, "steps"), map[event.OriginID]string{
+					"t1": "task_unit.log",
+					"t2": "task_quiet.log",
+					"r1": "task_flaky.log",
+					"r2": "task_flaky.log",
+				}, false)
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+				for _, ev := range []atc.Event{
+					event.InitializeTask{Origin: event.Origin{ID: "t1"}},
+					event.StartTask{Origin: event.Origin{ID: "t1"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "make", Args: []string{"test"}}}},
+					event.Log{Origin: event.Origin{ID: "t1"}, Payload: "ok\n"},
+					event.Log{Origin: event.Origin{ID: "r1"}, Payload: "attempt 1\n"},
+					event.Error{Origin: event.Origin{ID: "r1"}, Message: "boom"},
+					event.Log{Origin: event.Origin{ID: "r2"}, Payload: "attempt 2\n"},
+					event.Log{Origin: event.Origin{ID: "unknown"}, Payload: "from nowhere\n"},
+					event.Status{Status: atc.StatusSucceeded},
+				} {
+					gt.Expect(w.write(eventEnvelope{Data: ev, Event: ev.EventType(), Version: ev.Version()})).To(gomega.Succeed())
+				}
+				gt.Expect(w.close()).To(gomega.Succeed())
+			})
+
+			it.After(func() {
+				os.RemoveAll(dir)
+			})
+
+			readStep := func(name string) string {
+				contents, err := ioutil.ReadFile(filepath.Join(dir, "steps", name))
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				return string(contents)
+			}
+
+			it("renders each step's output into its own file", func() {
+				gt.Expect(readStep("task_unit.log")).To(gomega.Equal("\x1b[1minitializing\x1b[0m\n\x1b[1mrunning make test\x1b[0m\nok\n"))
+			})
+
+			it("puts every attempt of a retried step into one file", func() {
+				gt.Expect(readStep("task_flaky.log")).To(gomega.Equal("attempt 1\nboom\nattempt 2\n"))
+			})
+
+			it("writes an empty file for a step without output", func() {
+				gt.Expect(readStep("task_quiet.log")).To(gomega.BeEmpty())
+			})
+
+			it("writes nothing else", func() {
+				files, err := ioutil.ReadDir(filepath.Join(dir, "steps"))
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				gt.Expect(files).To(gomega.HaveLen(3))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,165 @@
+package in
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+
+	"fmt"
+	"io"
+	"time"
+)
+
+// Values for params.timestamped_log.
+const (
+	timestampsAbsolute = "absolute"
+	timestampsRelative = "relative"
+)
+
+// timestampedLogWriter writes the build's output the way fly renders it, with each line prefixed by the time of the
+// event which started it. Events only carry times to the second, and some events carry none, in which case the time
+// of the event before is used.
+type timestampedLogWriter struct {
+	dst   io.WriteCloser
+	stamp func(eventTime int64) string
+	plain bool
+
+	lastTime  int64
+	line      []byte
+	lineTime  int64
+	startTime int64
+}
+
+// newTimestampedLogWriter stamps lines with the time in UTC, or with the ISO-8601 duration elapsed since startTime.
+// If the build hasn't started, times are relative to the first event instead.
+func newTimestampedLogWriter(dst io.WriteCloser, timestamps string, startTime int64, plain bool) *timestampedLogWriter {
+	w := &timestampedLogWriter{
+		dst:       dst,
+		plain:     plain,
+		startTime: startTime,
+	}
+
+	if timestamps == timestampsRelative {
+		w.stamp = w.relativeStamp
+	} else {
+		w.stamp = absoluteStamp
+	}
+
+	return w
+}
+
+func absoluteStamp(eventTime int64) string {
+	return time.Unix(eventTime, 0).UTC().Format(time.RFC3339)
+}
+
+func (w *timestampedLogWriter) relativeStamp(eventTime int64) string {
+	elapsed := eventTime - w.startTime
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return isoDuration(elapsed)
+}
+
+// isoDuration gives a number of seconds as an ISO-8601 duration, like PT1H2M5S, leaving out parts which are zero.
+func isoDuration(seconds int64) string {
+	stamp := "PT"
+	if hours := seconds / 3600; hours > 0 {
+		stamp += fmt.Sprintf("%dH", hours)
+	}
+	if minutes := seconds / 60 % 60; minutes > 0 {
+		stamp += fmt.Sprintf("%dM", minutes)
+	}
+	if secs := seconds % 60; secs > 0 || stamp == "PT" {
+		stamp += fmt.Sprintf("%dS", secs)
+	}
+
+	return stamp
+}
+
+func (w *timestampedLogWriter) write(envelope eventEnvelope) error {
+	if eventTime := timeOf(envelope.Data); eventTime != 0 {
+		w.lastTime = eventTime
+		if w.startTime == 0 {
+			w.startTime = eventTime
+		}
+	}
+
+	if status, ok := envelope.Data.(event.Status); ok {
+		if status.Status == atc.StatusStarted {
+			return nil
+		}
+		return w.writeText(string(status.Status) + "\n")
+	}
+
+	_, text, output := stepOutput(envelope.Data)
+	if !output {
+		return nil
+	}
+
+	return w.writeText(text)
+}
+
+func timeOf(data atc.Event) int64 {
+	switch e := data.(type) {
+	case event.Log:
+		return e.Time
+	case event.InitializeTask:
+		return e.Time
+	case event.StartTask:
+		return e.Time
+	case event.FinishTask:
+		return e.Time
+	case event.Status:
+		return e.Time
+	default:
+		return 0
+	}
+}
+
+func (w *timestampedLogWriter) writeText(text string) error {
+	for _, b := range []byte(text) {
+		if len(w.line) == 0 {
+			w.lineTime = w.lastTime
+		}
+
+		if b != '\n' {
+			w.line = append(w.line, b)
+			continue
+		}
+
+		err := w.writeLine(true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *timestampedLogWriter) writeLine(newline bool) error {
+	line := w.line
+	if w.plain {
+		line = plainLine(line)
+	}
+
+	_, err := fmt.Fprintf(w.dst, "%s %s", w.stamp(w.lineTime), line)
+	if err == nil && newline {
+		_, err = io.WriteString(w.dst, "\n")
+	}
+
+	w.line = w.line[:0]
+	return err
+}
+
+// close writes out a final line which doesn't end in a newline.
+func (w *timestampedLogWriter) close() error {
+	if len(w.line) > 0 {
+		err := w.writeLine(false)
+		if err != nil {
+			w.dst.Close()
+			return err
+		}
+	}
+
+	return w.dst.Close()
+}
@@ -0,0 +1,76 @@
+package in
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+)
+
+func TestTimestampedLog(t *testing.T) {
+	spec.Run(t, "timestamped log", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var dst *closableBuffer
+
+		it.Before(func() {
+			dst = &closableBuffer{}
+		})
+
+		// 2018-09-01T12:00:00Z
+		const buildStart = 1535803200
+
+		render := func(w *timestampedLogWriter) string {
+			for _, ev := range []atc.Event{
+				event.Status{Status: atc.StatusStarted, Time: buildStart},
+				event.InitializeTask{Origin: event.Origin{ID: "t1"}, Time: buildStart + 2},
+				event.StartTask{Origin: event.Origin{ID: "t1"}, Time: buildStart + 3, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "make"}}},
+				event.Log{Origin: event.Origin{ID: "t1"}, Time: buildStart + 5, Payload: "compiling"},
+				event.Log{Origin: event.Origin{ID: "t1"}, Time: buildStart + 65, Payload: " done\ntesting\n"},
+				event.Error{Origin: event.Origin{ID: "t1"}, Message: "boom"},
+				event.Status{Status: atc.StatusErrored, Time: buildStart + 3725},
+			} {
+				gt.Expect(w.write(eventEnvelope{Data: ev, Event: ev.EventType(), Version: ev.Version()})).To(gomega.Succeed())
+			}
+			gt.Expect(w.close()).To(gomega.Succeed())
+			return dst.String()
+		}
+
+		when("timestamps are absolute", func() {
+			it("prefixes each line with the time of the event which started it, in UTC", func() {
+				gt.Expect(render(newTimestampedLogWriter(dst, timestampsAbsolute, buildStart, false))).To(gomega.Equal(
+					"2018-09-01T12:00:02Z \x1b[1minitializing\x1b[0m\n" +
+						"2018-09-01T12:00:03Z \x1b[1mrunning make\x1b[0m\n" +
+						"2018-09-01T12:00:05Z compiling done\n" +
+						"2018-09-01T12:01:05Z testing\n" +
+						"2018-09-01T12:01:05Z boom\n" +
+						"2018-09-01T13:02:05Z errored\n",
+				))
+				gt.Expect(dst.closed).To(gomega.BeTrue())
+			})
+		}, spec.Nested())
+
+		when("timestamps are relative", func() {
+			it("prefixes each line with the time since the build started", func() {
+				gt.Expect(render(newTimestampedLogWriter(dst, timestampsRelative, buildStart, true))).To(gomega.Equal(
+					"PT2S initializing\n" +
+						"PT3S running make\n" +
+						"PT5S compiling done\n" +
+						"PT1M5S testing\n" +
+						"PT1M5S boom\n" +
+						"PT1H2M5S errored\n",
+				))
+			})
+
+			it("leaves out parts of the duration which are zero", func() {
+				gt.Expect(isoDuration(0)).To(gomega.Equal("PT0S"))
+				gt.Expect(isoDuration(3600)).To(gomega.Equal("PT1H"))
+			})
+
+			it("counts from the first event if the build has no start time", func() {
+				gt.Expect(render(newTimestampedLogWriter(dst, timestampsRelative, 0, true))).To(gomega.HavePrefix("PT2S initializing\n"))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,487 @@
+package out
+
+import (
+	"github.com/concourse/atc"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/client"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+	"log"
+	"strings"
+
+	gc "github.com/concourse/go-concourse/concourse"
+
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type Outer interface {
+	Out() (*config.OutResponse, error)
+}
+
+type outer struct {
+	outRequest      *config.OutRequest
+	concourseClient gc.Client
+	logs            io.Writer
+}
+
+// Actions which can be given in params.action.
+const (
+	actionTrigger         = "trigger"
+	actionAbort           = "abort"
+	actionPausePipeline   = "pause_pipeline"
+	actionUnpausePipeline = "unpause_pipeline"
+	actionExposePipeline  = "expose_pipeline"
+	actionHidePipeline    = "hide_pipeline"
+	actionPauseJob        = "pause_job"
+	actionUnpauseJob      = "unpause_job"
+	actionPinResource     = "pin_resource"
+	actionUnpinResource   = "unpin_resource"
+	actionRerun           = "rerun"
+)
+
+var actions = []string{
+	actionTrigger,
+	actionAbort,
+	actionPausePipeline,
+	actionUnpausePipeline,
+	actionExposePipeline,
+	actionHidePipeline,
+	actionPauseJob,
+	actionUnpauseJob,
+	actionPinResource,
+	actionUnpinResource,
+	actionRerun,
+}
+
+var pipelineActions = map[string]func(gc.Team, string) (bool, error){
+	actionPausePipeline:   gc.Team.PausePipeline,
+	actionUnpausePipeline: gc.Team.UnpausePipeline,
+	actionExposePipeline:  gc.Team.ExposePipeline,
+	actionHidePipeline:    gc.Team.HidePipeline,
+}
+
+var jobActions = map[string]func(gc.Team, string, string) (bool, error){
+	actionPauseJob:   gc.Team.PauseJob,
+	actionUnpauseJob: gc.Team.UnpauseJob,
+}
+
+const jobBuildsPageSize = 100
+
+func (o outer) Out() (*config.OutResponse, error) {
+	if o.outRequest.Source.EnableTracing {
+		log.Printf("Received OutRequest: %+v", o.outRequest)
+	}
+
+	action := o.outRequest.Params.Action
+	if pipelineAction, ok := pipelineActions[action]; ok {
+		return o.actOnPipeline(pipelineAction)
+	}
+	if jobAction, ok := jobActions[action]; ok {
+		return o.actOnJob(jobAction)
+	}
+
+	switch action {
+	case actionTrigger:
+		return o.trigger()
+	case actionAbort:
+		return o.abort()
+	case actionPinResource:
+		return o.pinResource()
+	case actionUnpinResource:
+		return o.unpinResource()
+	case actionRerun:
+		return o.rerun()
+	case "":
+		return nil, fmt.Errorf("params.action must be set, expected one of %s", expectedActions())
+	default:
+		return nil, fmt.Errorf("unknown action '%s' in params.action, expected one of %s", o.outRequest.Params.Action, expectedActions())
+	}
+}
+
+func expectedActions() string {
+	quoted := make([]string, len(actions))
+	for i, action := range actions {
+		quoted[i] = fmt.Sprintf("'%s'", action)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func NewOuter(input *config.OutRequest) (Outer, error) {
+	concourse, err := client.New(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOuterUsingClient(input, concourse), nil
+}
+
+func NewOuterUsingClient(input *config.OutRequest, client gc.Client) Outer {
+	input.Source.ConcourseUrl = strings.TrimSuffix(input.Source.ConcourseUrl, "/")
+
+	return outer{
+		outRequest:      input,
+		concourseClient: client,
+		logs:            os.Stderr,
+	}
+}
+
+// trigger starts a new build of the job, as if someone had pressed the + button in the web UI, and with params.wait
+// follows it to the end.
+func (o outer) trigger() (*config.OutResponse, error) {
+	team, pipeline, job := o.team(), o.pipeline(), o.job()
+	if team == "" || pipeline == "" || job == "" {
+		return nil, fmt.Errorf("team, pipeline and job must be set in source or params to trigger a build")
+	}
+
+	_, err := o.waitTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	build, err := o.concourseClient.Team(team).CreateJobBuild(pipeline, job)
+	if err != nil {
+		return nil, fmt.Errorf("could not trigger a build of '%s/%s' in team '%s': %s", pipeline, job, team, err.Error())
+	}
+
+	build, err = o.waitIfAsked(build)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.responseFor(build), nil
+}
+
+// abort stops either the build fetched by an earlier get into params.build, or every running build of the job.
+func (o outer) abort() (*config.OutResponse, error) {
+	params := o.outRequest.Params
+	if params.Build != "" && params.AllRunning {
+		return nil, fmt.Errorf("only one of build or all_running can be set to abort builds")
+	}
+
+	if params.AllRunning {
+		return o.abortAllRunning()
+	}
+
+	if params.Build == "" {
+		return nil, fmt.Errorf("either build or all_running must be set in params to abort builds")
+	}
+
+	buildId, err := o.readBuildId()
+	if err != nil {
+		return nil, err
+	}
+
+	build, found, err := o.concourseClient.Build(buildId)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching build '%s': %s", buildId, err.Error())
+	}
+	if !found {
+		return nil, fmt.Errorf("server could not find build '%s'", buildId)
+	}
+
+	err = o.concourseClient.AbortBuild(buildId)
+	if err != nil {
+		return nil, fmt.Errorf("could not abort build '%s': %s", buildId, err.Error())
+	}
+
+	aborted, err := o.refetch(build)
+	if err != nil {
+		return nil, err
+	}
+
+	response := o.responseFor(aborted)
+	response.Metadata = append(response.Metadata, abortedBuildsMetadata([]atc.Build{build}))
+	return response, nil
+}
+
+// abortAllRunning aborts every started or pending build of the job. Running builds are the most recent ones, so
+// pages of older builds are only fetched while they still contain running builds.
+func (o outer) abortAllRunning() (*config.OutResponse, error) {
+	team, pipeline, job := o.team(), o.pipeline(), o.job()
+	if team == "" || pipeline == "" || job == "" {
+		return nil, fmt.Errorf("team, pipeline and job must be set in source or params to abort all running builds")
+	}
+	concourseTeam := o.concourseClient.Team(team)
+
+	var latest atc.Build
+	var hasBuilds bool
+	aborted := make([]atc.Build, 0)
+	page := gc.Page{Limit: jobBuildsPageSize}
+	for {
+		builds, pagination, found, err := concourseTeam.JobBuilds(pipeline, job, page)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve builds for pipeline/job '%s/%s': %s", pipeline, job, err.Error())
+		}
+		if !found {
+			return nil, fmt.Errorf("server could not find pipeline/job '%s/%s'", pipeline, job)
+		}
+
+		if !hasBuilds && len(builds) > 0 {
+			latest = builds[0]
+			hasBuilds = true
+		}
+
+		running := 0
+		for _, b := range builds {
+			if b.Status != string(atc.StatusStarted) && b.Status != string(atc.StatusPending) {
+				continue
+			}
+			running++
+
+			err = o.concourseClient.AbortBuild(strconv.Itoa(b.ID))
+			if err != nil {
+				return nil, fmt.Errorf("could not abort build '%d': %s", b.ID, err.Error())
+			}
+			aborted = append(aborted, b)
+		}
+
+		if running == 0 || pagination.Next == nil {
+			break
+		}
+		page = *pagination.Next
+	}
+
+	// there has to be a version, so when nothing was running it's the job's latest build
+	var response *config.OutResponse
+	switch {
+	case len(aborted) > 0:
+		build, err := o.refetch(aborted[0])
+		if err != nil {
+			return nil, err
+		}
+		response = o.responseFor(build)
+	case hasBuilds:
+		response = o.responseFor(latest)
+	default:
+		return nil, fmt.Errorf("pipeline/job '%s/%s' has no builds", pipeline, job)
+	}
+
+	response.Metadata = append(response.Metadata, abortedBuildsMetadata(aborted))
+	return response, nil
+}
+
+// actOnPipeline pauses, unpauses, exposes or hides the pipeline. The pipeline's latest build becomes the version,
+// and is looked up first so that nothing changes if the pipeline can't be found.
+func (o outer) actOnPipeline(pipelineAction func(gc.Team, string) (bool, error)) (*config.OutResponse, error) {
+	action := o.outRequest.Params.Action
+	team, pipeline := o.team(), o.pipeline()
+	if team == "" || pipeline == "" {
+		return nil, fmt.Errorf("team and pipeline must be set in source or params to %s", describe(action))
+	}
+	concourseTeam := o.concourseClient.Team(team)
+
+	latest, hasBuilds, err := o.latestBuild(concourseTeam, pipeline, "")
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := pipelineAction(concourseTeam, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("could not %s '%s': %s", describe(action), pipeline, err.Error())
+	}
+	if !found {
+		return nil, fmt.Errorf("server could not find pipeline '%s'", pipeline)
+	}
+
+	response := o.responseForLatest(latest, hasBuilds, team, pipeline, "")
+	response.Metadata = append(response.Metadata, config.VersionMetadataField{Name: action, Value: pipeline})
+	return response, nil
+}
+
+// actOnJob pauses or unpauses the job, with the job's latest build as the version.
+func (o outer) actOnJob(jobAction func(gc.Team, string, string) (bool, error)) (*config.OutResponse, error) {
+	action := o.outRequest.Params.Action
+	team, pipeline, job := o.team(), o.pipeline(), o.job()
+	if team == "" || pipeline == "" || job == "" {
+		return nil, fmt.Errorf("team, pipeline and job must be set in source or params to %s", describe(action))
+	}
+	concourseTeam := o.concourseClient.Team(team)
+
+	latest, hasBuilds, err := o.latestBuild(concourseTeam, pipeline, job)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := jobAction(concourseTeam, pipeline, job)
+	if err != nil {
+		return nil, fmt.Errorf("could not %s '%s/%s': %s", describe(action), pipeline, job, err.Error())
+	}
+	if !found {
+		return nil, fmt.Errorf("server could not find pipeline/job '%s/%s'", pipeline, job)
+	}
+
+	response := o.responseForLatest(latest, hasBuilds, team, pipeline, job)
+	response.Metadata = append(response.Metadata, config.VersionMetadataField{Name: action, Value: fmt.Sprintf("%s/%s", pipeline, job)})
+	return response, nil
+}
+
+// latestBuild finds the most recent build of the job or, if no job is given, of the pipeline. It is false if there
+// are no builds yet.
+func (o outer) latestBuild(concourseTeam gc.Team, pipeline, job string) (atc.Build, bool, error) {
+	var builds []atc.Build
+	var found bool
+	var err error
+
+	if job == "" {
+		builds, _, found, err = concourseTeam.PipelineBuilds(pipeline, gc.Page{Limit: 1})
+		if err != nil {
+			return atc.Build{}, false, fmt.Errorf("could not retrieve builds for pipeline '%s': %s", pipeline, err.Error())
+		}
+		if !found {
+			return atc.Build{}, false, fmt.Errorf("server could not find pipeline '%s'", pipeline)
+		}
+	} else {
+		builds, _, found, err = concourseTeam.JobBuilds(pipeline, job, gc.Page{Limit: 1})
+		if err != nil {
+			return atc.Build{}, false, fmt.Errorf("could not retrieve builds for pipeline/job '%s/%s': %s", pipeline, job, err.Error())
+		}
+		if !found {
+			return atc.Build{}, false, fmt.Errorf("server could not find pipeline/job '%s/%s'", pipeline, job)
+		}
+	}
+
+	if len(builds) == 0 {
+		return atc.Build{}, false, nil
+	}
+
+	return builds[0], true, nil
+}
+
+// responseForLatest describes the latest build of the pipeline or job which was acted upon. One which has never run
+// has no build to describe, so the version names the pipeline or job instead, without a build_id.
+func (o outer) responseForLatest(latest atc.Build, hasBuilds bool, team, pipeline, job string) *config.OutResponse {
+	if hasBuilds {
+		return o.responseFor(latest)
+	}
+
+	return &config.OutResponse{
+		Version:  config.Version{Team: team, Pipeline: pipeline, Job: job},
+		Metadata: []config.VersionMetadataField{},
+	}
+}
+
+// describe turns an action like pause_pipeline into words for error messages.
+func describe(action string) string {
+	return strings.Replace(action, "_", " ", -1)
+}
+
+// readBuildId reads the global build number which an earlier get wrote into the params.build directory.
+func (o outer) readBuildId() (string, error) {
+	path := filepath.Join(o.outRequest.WorkingDirectory, o.outRequest.Params.Build, "global_number")
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read build ID from '%s': %s", path, err.Error())
+	}
+
+	buildId := strings.TrimSpace(string(contents))
+	_, err = strconv.Atoi(buildId)
+	if err != nil {
+		return "", fmt.Errorf("'%s' does not contain a build ID: '%s'", path, buildId)
+	}
+
+	return buildId, nil
+}
+
+// refetch fetches the build again after acting on it, so that its version describes it as it is afterwards.
+func (o outer) refetch(build atc.Build) (atc.Build, error) {
+	buildId := strconv.Itoa(build.ID)
+	refetched, found, err := o.concourseClient.Build(buildId)
+	if err != nil {
+		return atc.Build{}, fmt.Errorf("error while fetching build '%s': %s", buildId, err.Error())
+	}
+	if !found {
+		return atc.Build{}, fmt.Errorf("server could not find build '%s'", buildId)
+	}
+
+	return refetched, nil
+}
+
+func abortedBuildsMetadata(builds []atc.Build) config.VersionMetadataField {
+	ids := make([]string, len(builds))
+	for i, b := range builds {
+		ids[i] = strconv.Itoa(b.ID)
+	}
+
+	value := strings.Join(ids, ", ")
+	if value == "" {
+		value = "none"
+	}
+
+	return config.VersionMetadataField{Name: "aborted_builds", Value: value}
+}
+
+// responseFor describes a build which the action created or acted upon. Check only gives a version to a build once
+// it has finished, or once it has started when include_running is set. Other builds would not keep the status and
+// phase they have now, so those go in the metadata rather than in the version.
+func (o outer) responseFor(build atc.Build) *config.OutResponse {
+	version := config.VersionOf(build, o.outRequest.Source.IncludeRunning)
+	metadata := []config.VersionMetadataField{
+		{Name: "build_url", Value: o.buildUrl(build)},
+	}
+
+	// the version only has the status once the build has finished, but the put can still show it
+	if !o.checkWouldEmit(build) {
+		metadata = append(metadata, config.VersionMetadataField{Name: "status", Value: build.Status})
+	}
+
+	return &config.OutResponse{
+		Version:  version,
+		Metadata: metadata,
+	}
+}
+
+// checkWouldEmit is true when check gives the build a version in its current status.
+func (o outer) checkWouldEmit(build atc.Build) bool {
+	switch build.Status {
+	case string(atc.StatusPending):
+		return false
+	case string(atc.StatusStarted):
+		return o.outRequest.Source.IncludeRunning
+	default:
+		return true
+	}
+}
+
+func (o outer) buildUrl(build atc.Build) string {
+	if build.OneOff() {
+		return fmt.Sprintf("%s/builds/%d", o.outRequest.Source.ConcourseUrl, build.ID)
+	}
+
+	return fmt.Sprintf(
+		"%s/teams/%s/pipelines/%s/jobs/%s/builds/%s",
+		o.outRequest.Source.ConcourseUrl,
+		build.TeamName,
+		build.PipelineName,
+		build.JobName,
+		build.Name,
+	)
+}
+
+// team, pipeline and job can be given in params, to act on something other than what the resource watches.
+func (o outer) team() string {
+	if o.outRequest.Params.Team != "" {
+		return o.outRequest.Params.Team
+	}
+
+	return o.outRequest.Source.Team
+}
+
+func (o outer) pipeline() string {
+	if o.outRequest.Params.Pipeline != "" {
+		return o.outRequest.Params.Pipeline
+	}
+
+	return o.outRequest.Source.Pipeline
+}
+
+func (o outer) job() string {
+	if o.outRequest.Params.Job != "" {
+		return o.outRequest.Params.Job
+	}
+
+	return o.outRequest.Source.Job
+}
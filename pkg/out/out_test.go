@@ -0,0 +1,568 @@
+package out_test
+
+import (
+	"github.com/concourse/go-concourse/concourse"
+	fakes "github.com/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/out"
+
+	"github.com/concourse/atc"
+
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func TestOutPkg(t *testing.T) {
+	spec.Run(t, "pkg/out", func(t *testing.T, when spec.G, it spec.S) {
+		source := config.Source{
+			ConcourseUrl: "https://example.com/",
+			Team:         "team",
+			Pipeline:     "pipeline",
+			Job:          "job",
+		}
+
+		when("the action is trigger", func() {
+			when("include_running is set", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+
+				it("gives the version the phase check would", func() {
+					faketeam.CreateJobBuildReturns(atc.Build{ID: 999, Status: string(atc.StatusStarted)}, nil)
+
+					runningSource := source
+					runningSource.IncludeRunning = true
+					response, err := out.NewOuterUsingClient(&config.OutRequest{
+						Source: runningSource,
+						Params: config.OutParams{Action: "trigger"},
+					}, fakeclient).Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					gt.Expect(response.Version).To(gomega.Equal(config.Version{BuildId: "999", Phase: "started"}))
+				})
+
+				it("gives a pending build the phase check will give it once it starts", func() {
+					faketeam.CreateJobBuildReturns(atc.Build{ID: 999, Status: string(atc.StatusPending)}, nil)
+
+					runningSource := source
+					runningSource.IncludeRunning = true
+					response, err := out.NewOuterUsingClient(&config.OutRequest{
+						Source: runningSource,
+						Params: config.OutParams{Action: "trigger"},
+					}, fakeclient).Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+
+					gt.Expect(response.Version).To(gomega.Equal(config.Version{BuildId: "999", Phase: "started"}))
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "status", Value: "pending"}))
+				})
+			}, spec.Nested())
+
+			when("the job can be triggered", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.OutResponse
+
+				it.Before(func() {
+					faketeam.CreateJobBuildReturns(atc.Build{
+						ID:           999,
+						Name:         "111",
+						TeamName:     "team",
+						PipelineName: "pipeline",
+						JobName:      "job",
+						Status:       string(atc.StatusPending),
+					}, nil)
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "trigger"},
+					}, fakeclient)
+					var err error
+					response, err = outer.Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("triggers the job given in source", func() {
+					gt.Expect(fakeclient.TeamArgsForCall(0)).To(gomega.Equal("team"))
+					pipeline, job := faketeam.CreateJobBuildArgsForCall(0)
+					gt.Expect(pipeline).To(gomega.Equal("pipeline"))
+					gt.Expect(job).To(gomega.Equal("job"))
+				})
+
+				it("returns the new build as the version, without a status until it finishes", func() {
+					gt.Expect(response.Version).To(gomega.Equal(config.Version{
+						BuildId:   "999",
+						Team:      "team",
+						Pipeline:  "pipeline",
+						Job:       "job",
+						JobNumber: "111",
+					}))
+				})
+
+				it("returns metadata with the build's status", func() {
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "status", Value: "pending"}))
+				})
+
+				it("returns metadata with the build URL", func() {
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "build_url", Value: "https://example.com/teams/team/pipelines/pipeline/jobs/job/builds/111"}))
+				})
+			}, spec.Nested())
+
+			when("the team, pipeline and job are given in params", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+
+				it.Before(func() {
+					faketeam.CreateJobBuildReturns(atc.Build{ID: 999}, nil)
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "trigger", Team: "other-team", Pipeline: "other-pipeline", Job: "other-job"},
+					}, fakeclient)
+					_, err := outer.Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("triggers that job instead", func() {
+					gt.Expect(fakeclient.TeamArgsForCall(0)).To(gomega.Equal("other-team"))
+					pipeline, job := faketeam.CreateJobBuildArgsForCall(0)
+					gt.Expect(pipeline).To(gomega.Equal("other-pipeline"))
+					gt.Expect(job).To(gomega.Equal("other-job"))
+				})
+			}, spec.Nested())
+
+			when("no job is given", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				var err error
+
+				it.Before(func() {
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: config.Source{ConcourseUrl: "https://example.com", Team: "team", Pipeline: "pipeline"},
+						Params: config.OutParams{Action: "trigger"},
+					}, fakeclient)
+					_, err = outer.Out()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err).To(gomega.MatchError("team, pipeline and job must be set in source or params to trigger a build"))
+				})
+			}, spec.Nested())
+
+			when("the job cannot be triggered", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var err error
+
+				it.Before(func() {
+					faketeam.CreateJobBuildReturns(atc.Build{}, fmt.Errorf("test error"))
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "trigger"},
+					}, fakeclient)
+					_, err = outer.Out()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err).To(gomega.MatchError("could not trigger a build of 'pipeline/job' in team 'team': test error"))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("the action is abort", func() {
+			when("a build from an earlier get is given", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				var response *config.OutResponse
+				var workingDirectory string
+
+				it.Before(func() {
+					var err error
+					workingDirectory, err = ioutil.TempDir("", "out")
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+					gt.Expect(os.Mkdir(filepath.Join(workingDirectory, "build"), os.ModePerm)).To(gomega.Succeed())
+					gt.Expect(ioutil.WriteFile(filepath.Join(workingDirectory, "build", "global_number"), []byte("999"), os.ModePerm)).To(gomega.Succeed())
+
+					fakeclient.BuildReturnsOnCall(0, atc.Build{ID: 999, Name: "111", TeamName: "team", PipelineName: "pipeline", JobName: "job", Status: string(atc.StatusStarted)}, true, nil)
+					fakeclient.BuildReturnsOnCall(1, atc.Build{ID: 999, Name: "111", TeamName: "team", PipelineName: "pipeline", JobName: "job", Status: string(atc.StatusAborted)}, true, nil)
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source:           source,
+						Params:           config.OutParams{Action: "abort", Build: "build"},
+						WorkingDirectory: workingDirectory,
+					}, fakeclient)
+					response, err = outer.Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it.After(func() {
+					os.RemoveAll(workingDirectory)
+				})
+
+				it("aborts that build", func() {
+					gt.Expect(fakeclient.AbortBuildCallCount()).To(gomega.Equal(1))
+					gt.Expect(fakeclient.AbortBuildArgsForCall(0)).To(gomega.Equal("999"))
+				})
+
+				it("returns the aborted build as the version, as it is once aborted", func() {
+					gt.Expect(fakeclient.BuildArgsForCall(1)).To(gomega.Equal("999"))
+					gt.Expect(response.Version.BuildId).To(gomega.Equal("999"))
+					gt.Expect(response.Version.Status).To(gomega.Equal("aborted"))
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "aborted_builds", Value: "999"}))
+				})
+			}, spec.Nested())
+
+			when("the build directory has no build ID", func() {
+				gt := gomega.NewGomegaWithT(t)
+				fakeclient := new(fakes.FakeClient)
+				var err error
+
+				it.Before(func() {
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source:           source,
+						Params:           config.OutParams{Action: "abort", Build: "missing"},
+						WorkingDirectory: os.TempDir(),
+					}, fakeclient)
+					_, err = outer.Out()
+				})
+
+				it("returns an error without aborting anything", func() {
+					gt.Expect(err.Error()).To(gomega.ContainSubstring("could not read build ID from"))
+					gt.Expect(fakeclient.AbortBuildCallCount()).To(gomega.BeZero())
+				})
+			}, spec.Nested())
+
+			when("all running builds of the job are to be aborted", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.OutResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturnsOnCall(0,
+						[]atc.Build{
+							{ID: 999, Status: string(atc.StatusPending)},
+							{ID: 888, Status: string(atc.StatusStarted)},
+						},
+						concourse.Pagination{Next: &concourse.Page{Until: 888, Limit: 100}},
+						true,
+						nil)
+
+					faketeam.JobBuildsReturnsOnCall(1,
+						[]atc.Build{
+							{ID: 777, Status: string(atc.StatusStarted)},
+							{ID: 666, Status: string(atc.StatusSucceeded)},
+						},
+						concourse.Pagination{Next: &concourse.Page{Until: 666, Limit: 100}},
+						true,
+						nil)
+
+					faketeam.JobBuildsReturnsOnCall(2,
+						[]atc.Build{
+							{ID: 555, Status: string(atc.StatusSucceeded)},
+						},
+						concourse.Pagination{Next: &concourse.Page{Until: 555, Limit: 100}},
+						true,
+						nil)
+
+					fakeclient.BuildReturns(atc.Build{ID: 999, Status: string(atc.StatusAborted)}, true, nil)
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "abort", AllRunning: true},
+					}, fakeclient)
+					var err error
+					response, err = outer.Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("aborts the started and pending builds", func() {
+					gt.Expect(fakeclient.AbortBuildCallCount()).To(gomega.Equal(3))
+					gt.Expect(fakeclient.AbortBuildArgsForCall(0)).To(gomega.Equal("999"))
+					gt.Expect(fakeclient.AbortBuildArgsForCall(1)).To(gomega.Equal("888"))
+					gt.Expect(fakeclient.AbortBuildArgsForCall(2)).To(gomega.Equal("777"))
+				})
+
+				it("stops looking once a page has no running builds", func() {
+					gt.Expect(faketeam.JobBuildsCallCount()).To(gomega.Equal(3))
+				})
+
+				it("returns the most recent aborted build as the version, as it is once aborted", func() {
+					gt.Expect(fakeclient.BuildArgsForCall(0)).To(gomega.Equal("999"))
+					gt.Expect(response.Version.BuildId).To(gomega.Equal("999"))
+					gt.Expect(response.Version.Status).To(gomega.Equal("aborted"))
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "aborted_builds", Value: "999, 888, 777"}))
+				})
+			}, spec.Nested())
+
+			when("nothing is running", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.OutResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturns(
+						[]atc.Build{{ID: 666, Status: string(atc.StatusSucceeded)}},
+						concourse.Pagination{},
+						true,
+						nil)
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "abort", AllRunning: true},
+					}, fakeclient)
+					var err error
+					response, err = outer.Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("returns the latest build as the version", func() {
+					gt.Expect(fakeclient.AbortBuildCallCount()).To(gomega.BeZero())
+					gt.Expect(response.Version.BuildId).To(gomega.Equal("666"))
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "aborted_builds", Value: "none"}))
+				})
+			}, spec.Nested())
+
+			when("neither a build nor all_running is given", func() {
+				gt := gomega.NewGomegaWithT(t)
+				var err error
+
+				it.Before(func() {
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "abort"},
+					}, new(fakes.FakeClient))
+					_, err = outer.Out()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err).To(gomega.MatchError("either build or all_running must be set in params to abort builds"))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("the action changes a pipeline", func() {
+			pipelineActions := []struct {
+				action    string
+				callCount func(*fakes.FakeTeam) int
+			}{
+				{"pause_pipeline", (*fakes.FakeTeam).PausePipelineCallCount},
+				{"unpause_pipeline", (*fakes.FakeTeam).UnpausePipelineCallCount},
+				{"expose_pipeline", (*fakes.FakeTeam).ExposePipelineCallCount},
+				{"hide_pipeline", (*fakes.FakeTeam).HidePipelineCallCount},
+			}
+
+			for _, pipelineAction := range pipelineActions {
+				action, callCount := pipelineAction.action, pipelineAction.callCount
+
+				when(action, func() {
+					gt := gomega.NewGomegaWithT(t)
+					faketeam := new(fakes.FakeTeam)
+					fakeclient := new(fakes.FakeClient)
+					fakeclient.TeamReturns(faketeam)
+					var response *config.OutResponse
+
+					it.Before(func() {
+						faketeam.PipelineBuildsReturns([]atc.Build{{ID: 999, Name: "111", TeamName: "team", PipelineName: "pipeline", JobName: "job"}}, concourse.Pagination{}, true, nil)
+						faketeam.PausePipelineReturns(true, nil)
+						faketeam.UnpausePipelineReturns(true, nil)
+						faketeam.ExposePipelineReturns(true, nil)
+						faketeam.HidePipelineReturns(true, nil)
+
+						outer := out.NewOuterUsingClient(&config.OutRequest{
+							Source: config.Source{ConcourseUrl: "https://example.com", Team: "team", Pipeline: "pipeline"},
+							Params: config.OutParams{Action: action},
+						}, fakeclient)
+						var err error
+						response, err = outer.Out()
+						gt.Expect(err).NotTo(gomega.HaveOccurred())
+					})
+
+					it("acts on the pipeline", func() {
+						gt.Expect(callCount(faketeam)).To(gomega.Equal(1))
+					})
+
+					it("returns the pipeline's latest build as the version", func() {
+						gt.Expect(response.Version.BuildId).To(gomega.Equal("999"))
+						gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: action, Value: "pipeline"}))
+					})
+				}, spec.Nested())
+			}
+
+			when("the pipeline is not found", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var err error
+
+				it.Before(func() {
+					faketeam.PipelineBuildsReturns([]atc.Build{{ID: 999}}, concourse.Pagination{}, true, nil)
+					faketeam.HidePipelineReturns(false, nil)
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "hide_pipeline"},
+					}, fakeclient)
+					_, err = outer.Out()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err).To(gomega.MatchError("server could not find pipeline 'pipeline'"))
+				})
+			}, spec.Nested())
+
+			when("the pipeline has no builds", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.OutResponse
+
+				it.Before(func() {
+					faketeam.PipelineBuildsReturns([]atc.Build{}, concourse.Pagination{}, true, nil)
+					faketeam.PausePipelineReturns(true, nil)
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "pause_pipeline"},
+					}, fakeclient)
+					var err error
+					response, err = outer.Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("pauses the pipeline", func() {
+					gt.Expect(faketeam.PausePipelineCallCount()).To(gomega.Equal(1))
+				})
+
+				it("returns a version naming the pipeline, without a build_id", func() {
+					gt.Expect(response.Version).To(gomega.Equal(config.Version{Team: "team", Pipeline: "pipeline"}))
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "pause_pipeline", Value: "pipeline"}))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("the action changes a job", func() {
+			when("pause_job", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var response *config.OutResponse
+
+				it.Before(func() {
+					faketeam.JobBuildsReturns([]atc.Build{{ID: 999}}, concourse.Pagination{}, true, nil)
+					faketeam.PauseJobReturns(true, nil)
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "pause_job"},
+					}, fakeclient)
+					var err error
+					response, err = outer.Out()
+					gt.Expect(err).NotTo(gomega.HaveOccurred())
+				})
+
+				it("pauses the job", func() {
+					pipeline, job := faketeam.PauseJobArgsForCall(0)
+					gt.Expect(pipeline).To(gomega.Equal("pipeline"))
+					gt.Expect(job).To(gomega.Equal("job"))
+				})
+
+				it("returns the job's latest build as the version", func() {
+					gt.Expect(response.Version.BuildId).To(gomega.Equal("999"))
+					gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "pause_job", Value: "pipeline/job"}))
+				})
+			}, spec.Nested())
+
+			when("unpause_job fails", func() {
+				gt := gomega.NewGomegaWithT(t)
+				faketeam := new(fakes.FakeTeam)
+				fakeclient := new(fakes.FakeClient)
+				fakeclient.TeamReturns(faketeam)
+				var err error
+
+				it.Before(func() {
+					faketeam.JobBuildsReturns([]atc.Build{{ID: 999}}, concourse.Pagination{}, true, nil)
+					faketeam.UnpauseJobReturns(false, fmt.Errorf("test error"))
+
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: source,
+						Params: config.OutParams{Action: "unpause_job"},
+					}, fakeclient)
+					_, err = outer.Out()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err).To(gomega.MatchError("could not unpause job 'pipeline/job': test error"))
+				})
+			}, spec.Nested())
+
+			when("no job is given", func() {
+				gt := gomega.NewGomegaWithT(t)
+				var err error
+
+				it.Before(func() {
+					outer := out.NewOuterUsingClient(&config.OutRequest{
+						Source: config.Source{ConcourseUrl: "https://example.com", Team: "team", Pipeline: "pipeline"},
+						Params: config.OutParams{Action: "pause_job"},
+					}, new(fakes.FakeClient))
+					_, err = outer.Out()
+				})
+
+				it("returns an error", func() {
+					gt.Expect(err).To(gomega.MatchError("team, pipeline and job must be set in source or params to pause job"))
+				})
+			}, spec.Nested())
+		}, spec.Nested())
+
+		when("no action is given", func() {
+			gt := gomega.NewGomegaWithT(t)
+			var err error
+
+			it.Before(func() {
+				outer := out.NewOuterUsingClient(&config.OutRequest{Source: source}, new(fakes.FakeClient))
+				_, err = outer.Out()
+			})
+
+			it("returns an error", func() {
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("params.action must be set"))
+			})
+		}, spec.Nested())
+
+		when("an unknown action is given", func() {
+			gt := gomega.NewGomegaWithT(t)
+			var err error
+
+			it.Before(func() {
+				outer := out.NewOuterUsingClient(&config.OutRequest{
+					Source: source,
+					Params: config.OutParams{Action: "explode"},
+				}, new(fakes.FakeClient))
+				_, err = outer.Out()
+			})
+
+			it("returns an error", func() {
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("unknown action 'explode' in params.action"))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
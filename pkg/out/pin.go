@@ -0,0 +1,224 @@
+package out
+
+import (
+	"github.com/concourse/atc"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+
+	gc "github.com/concourse/go-concourse/concourse"
+
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+)
+
+const resourceVersionsPageSize = 100
+
+// pinResource pins a resource in the pipeline to a version, as the pin button in the web UI does. The version
+// needs to be one that the pipeline has already seen.
+//
+// Pinning arrived in Concourse 5.0, after the go-concourse release this is built against, so the request is
+// sent directly.
+func (o outer) pinResource() (*config.OutResponse, error) {
+	team, pipeline, resource := o.team(), o.pipeline(), o.outRequest.Params.Resource
+	if team == "" || pipeline == "" || resource == "" {
+		return nil, fmt.Errorf("team, pipeline and resource must be set in source or params to pin a resource")
+	}
+	concourseTeam := o.concourseClient.Team(team)
+
+	version, err := o.versionToPin()
+	if err != nil {
+		return nil, err
+	}
+
+	latest, hasBuilds, err := o.latestBuild(concourseTeam, pipeline, "")
+	if err != nil {
+		return nil, err
+	}
+
+	versionId, err := o.findResourceVersion(concourseTeam, pipeline, resource, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.sendResourceRequest(team, pipeline, resource, fmt.Sprintf("versions/%d/pin", versionId))
+	if err != nil {
+		return nil, fmt.Errorf("could not pin resource '%s' in pipeline '%s': %s", resource, pipeline, err.Error())
+	}
+
+	versionJson, err := json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+
+	response := o.responseForLatest(latest, hasBuilds, team, pipeline, "")
+	response.Metadata = append(response.Metadata,
+		config.VersionMetadataField{Name: "pinned_resource", Value: resource},
+		config.VersionMetadataField{Name: "pinned_version", Value: string(versionJson)},
+	)
+	return response, nil
+}
+
+// unpinResource lets the resource follow its latest version again.
+func (o outer) unpinResource() (*config.OutResponse, error) {
+	team, pipeline, resource := o.team(), o.pipeline(), o.outRequest.Params.Resource
+	if team == "" || pipeline == "" || resource == "" {
+		return nil, fmt.Errorf("team, pipeline and resource must be set in source or params to unpin a resource")
+	}
+
+	latest, hasBuilds, err := o.latestBuild(o.concourseClient.Team(team), pipeline, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.sendResourceRequest(team, pipeline, resource, "unpin")
+	if err != nil {
+		return nil, fmt.Errorf("could not unpin resource '%s' in pipeline '%s': %s", resource, pipeline, err.Error())
+	}
+
+	response := o.responseForLatest(latest, hasBuilds, team, pipeline, "")
+	response.Metadata = append(response.Metadata, config.VersionMetadataField{Name: "unpinned_resource", Value: resource})
+	return response, nil
+}
+
+// versionToPin comes from params.version or from params.version_file. The file can hold either a version, or the
+// resources.json written by a get of this resource, in which case the version of the build's input from the same
+// resource is used.
+func (o outer) versionToPin() (atc.Version, error) {
+	params := o.outRequest.Params
+	if len(params.Version) > 0 && params.VersionFile != "" {
+		return nil, fmt.Errorf("only one of version or version_file can be set to pin a resource")
+	}
+	if len(params.Version) > 0 {
+		return atc.Version(params.Version), nil
+	}
+	if params.VersionFile == "" {
+		return nil, fmt.Errorf("either version or version_file must be set in params to pin a resource")
+	}
+
+	path := filepath.Join(o.outRequest.WorkingDirectory, params.VersionFile)
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read version_file '%s': %s", path, err.Error())
+	}
+
+	var resources atc.BuildInputsOutputs
+	err = json.Unmarshal(contents, &resources)
+	if err == nil && resources.Inputs != nil {
+		for _, input := range resources.Inputs {
+			if input.Resource == params.Resource {
+				return input.Version, nil
+			}
+		}
+
+		return nil, fmt.Errorf("'%s' has no input from resource '%s'", path, params.Resource)
+	}
+
+	var version atc.Version
+	err = json.Unmarshal(contents, &version)
+	if err != nil || len(version) == 0 {
+		return nil, fmt.Errorf("'%s' does not contain a version or the resources.json of a build", path)
+	}
+
+	return version, nil
+}
+
+// findResourceVersion pages through the versions of the resource, newest first, for one which has every field
+// given in version.
+func (o outer) findResourceVersion(concourseTeam gc.Team, pipeline, resource string, version atc.Version) (int, error) {
+	page := gc.Page{Limit: resourceVersionsPageSize}
+
+	for {
+		versions, pagination, found, err := concourseTeam.ResourceVersions(pipeline, resource, page)
+		if err != nil {
+			return 0, fmt.Errorf("could not retrieve versions of resource '%s' in pipeline '%s': %s", resource, pipeline, err.Error())
+		}
+		if !found {
+			return 0, fmt.Errorf("server could not find resource '%s' in pipeline '%s'", resource, pipeline)
+		}
+
+		for _, v := range versions {
+			if hasFields(v.Version, version) {
+				return v.ID, nil
+			}
+		}
+
+		if pagination.Next == nil {
+			return 0, fmt.Errorf("resource '%s' in pipeline '%s' has no version matching %v", resource, pipeline, version)
+		}
+		page = *pagination.Next
+	}
+}
+
+func hasFields(version, fields atc.Version) bool {
+	for k, v := range fields {
+		if version[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pinnedVersion is the version the resource is pinned to, or nil if it isn't pinned. The resources go-concourse
+// knows about predate pinning, so the resource is fetched directly.
+func (o outer) pinnedVersion(team, pipeline, resource string) (atc.Version, error) {
+	response, err := o.sendApiRequest(http.MethodGet, resourcePath(team, pipeline, resource))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server responded with '%s'", response.Status)
+	}
+
+	var pinned struct {
+		PinnedVersion atc.Version `json:"pinned_version"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&pinned)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse resource: %s", err.Error())
+	}
+
+	return pinned.PinnedVersion, nil
+}
+
+func resourcePath(team, pipeline, resource string) string {
+	return fmt.Sprintf(
+		"teams/%s/pipelines/%s/resources/%s",
+		url.PathEscape(team),
+		url.PathEscape(pipeline),
+		url.PathEscape(resource),
+	)
+}
+
+func (o outer) sendResourceRequest(team, pipeline, resource, action string) error {
+	response, err := o.sendApiRequest(http.MethodPut, fmt.Sprintf("%s/%s", resourcePath(team, pipeline, resource), action))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("server responded with '%s', it may be older than Concourse 5.0, which added pinning", response.Status)
+	default:
+		return fmt.Errorf("server responded with '%s'", response.Status)
+	}
+}
+
+// sendApiRequest is for endpoints which are newer than the go-concourse release this is built against. The
+// client's HTTP client already carries the credentials.
+func (o outer) sendApiRequest(method, path string) (*http.Response, error) {
+	request, err := http.NewRequest(method, fmt.Sprintf("%s/api/v1/%s", o.concourseClient.URL(), path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.concourseClient.HTTPClient().Do(request)
+}
@@ -0,0 +1,204 @@
+package out_test
+
+import (
+	"github.com/concourse/go-concourse/concourse"
+	fakes "github.com/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/out"
+
+	"github.com/concourse/atc"
+
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func TestPinning(t *testing.T) {
+	spec.Run(t, "pin_resource and unpin_resource", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var server *ghttp.Server
+		var faketeam *fakes.FakeTeam
+		var fakeclient *fakes.FakeClient
+		var requests []string
+		source := config.Source{
+			ConcourseUrl: "https://example.com",
+			Team:         "team",
+			Pipeline:     "pipeline",
+		}
+
+		it.Before(func() {
+			requests = nil
+			server = ghttp.NewServer()
+			server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.Path)
+			}))
+			server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/source-code/unpin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.Path)
+			}))
+
+			faketeam = new(fakes.FakeTeam)
+			faketeam.PipelineBuildsReturns([]atc.Build{{ID: 999}}, concourse.Pagination{}, true, nil)
+			faketeam.ResourceVersionsReturnsOnCall(0,
+				[]atc.VersionedResource{
+					{ID: 33, Version: atc.Version{"ref": "def456"}},
+				},
+				concourse.Pagination{Next: &concourse.Page{Until: 33, Limit: 100}},
+				true,
+				nil)
+			faketeam.ResourceVersionsReturnsOnCall(1,
+				[]atc.VersionedResource{
+					{ID: 22, Version: atc.Version{"ref": "abc123", "branch": "master"}},
+				},
+				concourse.Pagination{},
+				true,
+				nil)
+
+			fakeclient = new(fakes.FakeClient)
+			fakeclient.TeamReturns(faketeam)
+			fakeclient.URLReturns(server.URL())
+			fakeclient.HTTPClientReturns(http.DefaultClient)
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		when("pinning to a version given inline", func() {
+			var response *config.OutResponse
+
+			it.Before(func() {
+				outer := out.NewOuterUsingClient(&config.OutRequest{
+					Source: source,
+					Params: config.OutParams{Action: "pin_resource", Resource: "source-code", Version: map[string]string{"ref": "abc123"}},
+				}, fakeclient)
+				var err error
+				response, err = outer.Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("pins the resource to the matching version", func() {
+				gt.Expect(requests).To(gomega.Equal([]string{"/api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin"}))
+			})
+
+			it("returns the pipeline's latest build as the version", func() {
+				gt.Expect(response.Version.BuildId).To(gomega.Equal("999"))
+				gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "pinned_version", Value: `{"ref":"abc123"}`}))
+			})
+		}, spec.Nested())
+
+		when("pinning to the version used by a fetched build", func() {
+			var workingDirectory string
+
+			it.Before(func() {
+				var err error
+				workingDirectory, err = ioutil.TempDir("", "out")
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+				gt.Expect(os.Mkdir(filepath.Join(workingDirectory, "build"), os.ModePerm)).To(gomega.Succeed())
+				gt.Expect(ioutil.WriteFile(
+					filepath.Join(workingDirectory, "build", "resources.json"),
+					[]byte(`{"concourse_build_resource":{"release":"v0.99.11"},"inputs":[{"name":"code","resource":"source-code","version":{"ref":"abc123"}}],"outputs":[]}`),
+					os.ModePerm,
+				)).To(gomega.Succeed())
+
+				outer := out.NewOuterUsingClient(&config.OutRequest{
+					Source:           source,
+					Params:           config.OutParams{Action: "pin_resource", Resource: "source-code", VersionFile: "build/resources.json"},
+					WorkingDirectory: workingDirectory,
+				}, fakeclient)
+				_, err = outer.Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it.After(func() {
+				os.RemoveAll(workingDirectory)
+			})
+
+			it("pins the resource to the version of the build's input", func() {
+				gt.Expect(requests).To(gomega.Equal([]string{"/api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin"}))
+			})
+		}, spec.Nested())
+
+		when("no version matches", func() {
+			var err error
+
+			it.Before(func() {
+				outer := out.NewOuterUsingClient(&config.OutRequest{
+					Source: source,
+					Params: config.OutParams{Action: "pin_resource", Resource: "source-code", Version: map[string]string{"ref": "fff000"}},
+				}, fakeclient)
+				_, err = outer.Out()
+			})
+
+			it("returns an error without pinning anything", func() {
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("resource 'source-code' in pipeline 'pipeline' has no version matching"))
+				gt.Expect(requests).To(gomega.BeEmpty())
+			})
+		}, spec.Nested())
+
+		when("the server does not support pinning", func() {
+			var err error
+
+			it.Before(func() {
+				server.SetAllowUnhandledRequests(true)
+				server.SetUnhandledRequestStatusCode(http.StatusNotFound)
+				faketeam.ResourceVersionsReturnsOnCall(0, []atc.VersionedResource{{ID: 44, Version: atc.Version{"ref": "abc123"}}}, concourse.Pagination{}, true, nil)
+
+				outer := out.NewOuterUsingClient(&config.OutRequest{
+					Source: source,
+					Params: config.OutParams{Action: "pin_resource", Resource: "source-code", Version: map[string]string{"ref": "abc123"}},
+				}, fakeclient)
+				_, err = outer.Out()
+			})
+
+			it("returns an error", func() {
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("could not pin resource 'source-code' in pipeline 'pipeline': server responded with '404 Not Found', it may be older than Concourse 5.0"))
+			})
+		}, spec.Nested())
+
+		when("unpinning", func() {
+			it.Before(func() {
+				outer := out.NewOuterUsingClient(&config.OutRequest{
+					Source: source,
+					Params: config.OutParams{Action: "unpin_resource", Resource: "source-code"},
+				}, fakeclient)
+				_, err := outer.Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("unpins the resource", func() {
+				gt.Expect(requests).To(gomega.Equal([]string{"/api/v1/teams/team/pipelines/pipeline/resources/source-code/unpin"}))
+			})
+		}, spec.Nested())
+
+		when("the pipeline has no builds", func() {
+			var response *config.OutResponse
+
+			it.Before(func() {
+				faketeam.PipelineBuildsReturns([]atc.Build{}, concourse.Pagination{}, true, nil)
+
+				outer := out.NewOuterUsingClient(&config.OutRequest{
+					Source: source,
+					Params: config.OutParams{Action: "pin_resource", Resource: "source-code", Version: map[string]string{"ref": "abc123"}},
+				}, fakeclient)
+				var err error
+				response, err = outer.Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("pins the resource", func() {
+				gt.Expect(requests).To(gomega.Equal([]string{"/api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin"}))
+			})
+
+			it("returns a version naming the pipeline, without a build_id", func() {
+				gt.Expect(response.Version).To(gomega.Equal(config.Version{Team: "team", Pipeline: "pipeline"}))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,240 @@
+package out
+
+import (
+	"github.com/concourse/atc"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How long a rerun with pinned inputs waits for the scheduler to start the new build, which is when its inputs are
+// decided, before unpinning.
+var (
+	rerunSchedulingTimeout = 5 * time.Minute
+	rerunPollInterval      = 2 * time.Second
+)
+
+type pinnedInput struct {
+	resource  string
+	versionId int
+}
+
+// rerun triggers the job of the build fetched into params.build again, with the same input versions. Servers with
+// a rerun endpoint do this themselves. For older ones, the inputs are pinned to the versions recorded in the
+// resources.json of the earlier get, the job is triggered, and the inputs which were pinned for it are unpinned once
+// the new build starts.
+func (o outer) rerun() (*config.OutResponse, error) {
+	if o.outRequest.Params.Build == "" {
+		return nil, fmt.Errorf("build must be set in params to rerun a build")
+	}
+
+	_, err := o.waitTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	buildId, err := o.readBuildId()
+	if err != nil {
+		return nil, err
+	}
+
+	original, found, err := o.concourseClient.Build(buildId)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching build '%s': %s", buildId, err.Error())
+	}
+	if !found {
+		return nil, fmt.Errorf("server could not find build '%s'", buildId)
+	}
+	if original.OneOff() {
+		return nil, fmt.Errorf("build '%s' is a one-off build, which has no job to rerun", buildId)
+	}
+
+	rerun, supported, err := o.rerunWithEndpoint(original)
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		rerun, err = o.rerunWithPinnedInputs(original)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rerun, err = o.waitIfAsked(rerun)
+	if err != nil {
+		return nil, err
+	}
+
+	response := o.responseFor(rerun)
+	response.Metadata = append(response.Metadata, config.VersionMetadataField{Name: "rerun_of", Value: o.buildUrl(original)})
+	return response, nil
+}
+
+// rerunWithEndpoint asks the server to rerun the build. It reports whether the server has a rerun endpoint at all.
+func (o outer) rerunWithEndpoint(original atc.Build) (atc.Build, bool, error) {
+	response, err := o.sendApiRequest(http.MethodPost, fmt.Sprintf(
+		"teams/%s/pipelines/%s/jobs/%s/builds/%s",
+		url.PathEscape(original.TeamName),
+		url.PathEscape(original.PipelineName),
+		url.PathEscape(original.JobName),
+		url.PathEscape(original.Name),
+	))
+	if err != nil {
+		return atc.Build{}, false, fmt.Errorf("could not rerun build '%d': %s", original.ID, err.Error())
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return atc.Build{}, false, nil
+	default:
+		return atc.Build{}, false, fmt.Errorf("could not rerun build '%d': server responded with '%s'", original.ID, response.Status)
+	}
+
+	var rerun atc.Build
+	err = json.NewDecoder(response.Body).Decode(&rerun)
+	if err != nil {
+		return atc.Build{}, false, fmt.Errorf("could not parse rerun of build '%d': %s", original.ID, err.Error())
+	}
+
+	return rerun, true, nil
+}
+
+func (o outer) rerunWithPinnedInputs(original atc.Build) (atc.Build, error) {
+	team, pipeline, job := original.TeamName, original.PipelineName, original.JobName
+	concourseTeam := o.concourseClient.Team(team)
+
+	inputs, err := o.readInputs()
+	if err != nil {
+		return atc.Build{}, err
+	}
+
+	pins := make([]pinnedInput, 0, len(inputs))
+	for _, input := range inputs {
+		// pins made by someone else are left alone: there's nothing to do if it's to the version the build used,
+		// and replacing it with another, or unpinning it afterwards, would undo their pin
+		current, err := o.pinnedVersion(team, pipeline, input.Resource)
+		if err != nil {
+			return atc.Build{}, fmt.Errorf("could not check whether resource '%s' in pipeline '%s' is pinned: %s", input.Resource, pipeline, err.Error())
+		}
+		if current != nil {
+			if !hasFields(current, input.Version) {
+				return atc.Build{}, fmt.Errorf("resource '%s' in pipeline '%s' is pinned to %v, not %v as used by build '%d', so the build can't be rerun until it is unpinned", input.Resource, pipeline, current, input.Version, original.ID)
+			}
+			continue
+		}
+
+		versionId, err := o.findResourceVersion(concourseTeam, pipeline, input.Resource, input.Version)
+		if err != nil {
+			return atc.Build{}, err
+		}
+		pins = append(pins, pinnedInput{resource: input.Resource, versionId: versionId})
+	}
+
+	pinned := make([]string, 0, len(pins))
+	for _, pin := range pins {
+		err = o.sendResourceRequest(team, pipeline, pin.resource, fmt.Sprintf("versions/%d/pin", pin.versionId))
+		if err != nil {
+			err = fmt.Errorf("could not pin resource '%s' in pipeline '%s' to rerun build '%d': %s", pin.resource, pipeline, original.ID, err.Error())
+			return atc.Build{}, o.unpinAfterRerun(team, pipeline, pinned, err)
+		}
+		pinned = append(pinned, pin.resource)
+	}
+
+	rerun, err := concourseTeam.CreateJobBuild(pipeline, job)
+	if err != nil {
+		err = fmt.Errorf("could not trigger a build of '%s/%s' in team '%s': %s", pipeline, job, team, err.Error())
+		return atc.Build{}, o.unpinAfterRerun(team, pipeline, pinned, err)
+	}
+
+	err = o.waitUntilScheduled(rerun)
+	return rerun, o.unpinAfterRerun(team, pipeline, pinned, err)
+}
+
+// readInputs reads the inputs of the build from the resources.json written by the earlier get, one per resource.
+func (o outer) readInputs() ([]atc.PublicBuildInput, error) {
+	path := filepath.Join(o.outRequest.WorkingDirectory, o.outRequest.Params.Build, "resources.json")
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read build inputs from '%s': %s", path, err.Error())
+	}
+
+	var resources atc.BuildInputsOutputs
+	err = json.Unmarshal(contents, &resources)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse build inputs from '%s': %s", path, err.Error())
+	}
+
+	inputs := make([]atc.PublicBuildInput, 0, len(resources.Inputs))
+	seen := make(map[string]atc.Version)
+	for _, input := range resources.Inputs {
+		version, ok := seen[input.Resource]
+		if !ok {
+			seen[input.Resource] = input.Version
+			inputs = append(inputs, input)
+			continue
+		}
+		if !hasFields(version, input.Version) || !hasFields(input.Version, version) {
+			return nil, fmt.Errorf("build used more than one version of resource '%s', so it can't be rerun by pinning", input.Resource)
+		}
+	}
+
+	return inputs, nil
+}
+
+// waitUntilScheduled waits for the build to leave pending, since its inputs are only decided when it starts.
+func (o outer) waitUntilScheduled(build atc.Build) error {
+	buildId := strconv.Itoa(build.ID)
+	deadline := time.Now().Add(rerunSchedulingTimeout)
+
+	for {
+		current, found, err := o.concourseClient.Build(buildId)
+		if err != nil {
+			return fmt.Errorf("error while fetching build '%s': %s", buildId, err.Error())
+		}
+		if !found {
+			return fmt.Errorf("server could not find build '%s'", buildId)
+		}
+		if current.Status != string(atc.StatusPending) {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("build '%s' was still pending after %s, so it may not use the same inputs once it starts; check that the job is not paused", buildId, rerunSchedulingTimeout)
+		}
+		time.Sleep(rerunPollInterval)
+	}
+}
+
+// unpinAfterRerun unpins the resources which were pinned for the rerun, even when the rerun itself failed, and
+// returns the errors from both, so that nobody is left wondering why a resource is still pinned.
+func (o outer) unpinAfterRerun(team, pipeline string, pinned []string, rerunErr error) error {
+	unpinErrors := make([]string, 0)
+	for _, resource := range pinned {
+		err := o.sendResourceRequest(team, pipeline, resource, "unpin")
+		if err != nil {
+			unpinErrors = append(unpinErrors, fmt.Sprintf("'%s' (%s)", resource, err.Error()))
+		}
+	}
+
+	if len(unpinErrors) == 0 {
+		return rerunErr
+	}
+
+	unpinErr := fmt.Sprintf("could not unpin resources in pipeline '%s' after rerun: %s", pipeline, strings.Join(unpinErrors, ", "))
+	if rerunErr != nil {
+		return fmt.Errorf("%s; %s", rerunErr.Error(), unpinErr)
+	}
+
+	return errors.New(unpinErr)
+}
@@ -0,0 +1,262 @@
+package out_test
+
+import (
+	"github.com/concourse/go-concourse/concourse"
+	fakes "github.com/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/out"
+
+	"github.com/concourse/atc"
+
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func TestRerun(t *testing.T) {
+	spec.Run(t, "rerun", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var server *ghttp.Server
+		var faketeam *fakes.FakeTeam
+		var fakeclient *fakes.FakeClient
+		var workingDirectory string
+		var requests []string
+		var request *config.OutRequest
+
+		original := atc.Build{ID: 999, Name: "11", Status: "failed", TeamName: "team", PipelineName: "pipeline", JobName: "job"}
+		rerun := atc.Build{ID: 1000, Name: "12", Status: "pending", TeamName: "team", PipelineName: "pipeline", JobName: "job"}
+		recordRequest := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+		})
+
+		it.Before(func() {
+			requests = nil
+			server = ghttp.NewServer()
+			server.SetAllowUnhandledRequests(true)
+			server.SetUnhandledRequestStatusCode(http.StatusNotFound)
+
+			var err error
+			workingDirectory, err = ioutil.TempDir("", "out")
+			gt.Expect(err).NotTo(gomega.HaveOccurred())
+			gt.Expect(os.Mkdir(filepath.Join(workingDirectory, "build"), os.ModePerm)).To(gomega.Succeed())
+			gt.Expect(ioutil.WriteFile(filepath.Join(workingDirectory, "build", "global_number"), []byte("999"), os.ModePerm)).To(gomega.Succeed())
+			gt.Expect(ioutil.WriteFile(
+				filepath.Join(workingDirectory, "build", "resources.json"),
+				[]byte(`{"concourse_build_resource":{"release":"v0.99.11"},"inputs":[{"name":"code","resource":"source-code","version":{"ref":"abc123"}},{"name":"tools","resource":"tools","version":{"version":"1.2.3"}}],"outputs":[]}`),
+				os.ModePerm,
+			)).To(gomega.Succeed())
+
+			faketeam = new(fakes.FakeTeam)
+			faketeam.CreateJobBuildReturns(rerun, nil)
+			faketeam.ResourceVersionsStub = func(pipeline, resource string, page concourse.Page) ([]atc.VersionedResource, concourse.Pagination, bool, error) {
+				switch resource {
+				case "source-code":
+					return []atc.VersionedResource{{ID: 22, Version: atc.Version{"ref": "abc123"}}}, concourse.Pagination{}, true, nil
+				case "tools":
+					return []atc.VersionedResource{{ID: 33, Version: atc.Version{"version": "1.2.3"}}}, concourse.Pagination{}, true, nil
+				default:
+					return nil, concourse.Pagination{}, false, nil
+				}
+			}
+
+			fakeclient = new(fakes.FakeClient)
+			fakeclient.TeamReturns(faketeam)
+			fakeclient.URLReturns(server.URL())
+			fakeclient.HTTPClientReturns(http.DefaultClient)
+			fakeclient.BuildStub = func(buildId string) (atc.Build, bool, error) {
+				switch buildId {
+				case "999":
+					return original, true, nil
+				case "1000":
+					started := rerun
+					started.Status = "started"
+					return started, true, nil
+				default:
+					return atc.Build{}, false, nil
+				}
+			}
+
+			notPinned := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"name":"resource"}`))
+			})
+			server.RouteToHandler("GET", "/api/v1/teams/team/pipelines/pipeline/resources/source-code", notPinned)
+			server.RouteToHandler("GET", "/api/v1/teams/team/pipelines/pipeline/resources/tools", notPinned)
+
+			request = &config.OutRequest{
+				Source:           config.Source{ConcourseUrl: "https://example.com"},
+				Params:           config.OutParams{Action: "rerun", Build: "build"},
+				WorkingDirectory: workingDirectory,
+			}
+		})
+
+		it.After(func() {
+			server.Close()
+			os.RemoveAll(workingDirectory)
+		})
+
+		when("the server can rerun builds itself", func() {
+			var response *config.OutResponse
+
+			it.Before(func() {
+				server.RouteToHandler("POST", "/api/v1/teams/team/pipelines/pipeline/jobs/job/builds/11", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					recordRequest(w, r)
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode(rerun)
+				}))
+
+				var err error
+				response, err = out.NewOuterUsingClient(request, fakeclient).Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("asks the server to rerun the build, without pinning anything", func() {
+				gt.Expect(requests).To(gomega.Equal([]string{"POST /api/v1/teams/team/pipelines/pipeline/jobs/job/builds/11"}))
+				gt.Expect(faketeam.CreateJobBuildCallCount()).To(gomega.Equal(0))
+			})
+
+			it("returns the new build as the version", func() {
+				gt.Expect(response.Version).To(gomega.Equal(config.Version{BuildId: "1000", Team: "team", Pipeline: "pipeline", Job: "job", JobNumber: "12"}))
+				gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{
+					Name:  "rerun_of",
+					Value: "https://example.com/teams/team/pipelines/pipeline/jobs/job/builds/11",
+				}))
+			})
+		}, spec.Nested())
+
+		when("the server has no rerun endpoint", func() {
+			var response *config.OutResponse
+
+			it.Before(func() {
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin", recordRequest)
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/tools/versions/33/pin", recordRequest)
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/source-code/unpin", recordRequest)
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/tools/unpin", recordRequest)
+
+				var err error
+				response, err = out.NewOuterUsingClient(request, fakeclient).Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("pins the inputs, triggers the job, then unpins the inputs", func() {
+				gt.Expect(requests).To(gomega.Equal([]string{
+					"PUT /api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin",
+					"PUT /api/v1/teams/team/pipelines/pipeline/resources/tools/versions/33/pin",
+					"PUT /api/v1/teams/team/pipelines/pipeline/resources/source-code/unpin",
+					"PUT /api/v1/teams/team/pipelines/pipeline/resources/tools/unpin",
+				}))
+				gt.Expect(faketeam.CreateJobBuildCallCount()).To(gomega.Equal(1))
+			})
+
+			it("waits for the new build to start before unpinning", func() {
+				gt.Expect(fakeclient.BuildCallCount()).To(gomega.Equal(2))
+				gt.Expect(fakeclient.BuildArgsForCall(1)).To(gomega.Equal("1000"))
+			})
+
+			it("returns the new build as the version", func() {
+				gt.Expect(response.Version.BuildId).To(gomega.Equal("1000"))
+			})
+		}, spec.Nested())
+
+		when("an input is already pinned to the version the build used", func() {
+			it.Before(func() {
+				server.RouteToHandler("GET", "/api/v1/teams/team/pipelines/pipeline/resources/source-code", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`{"name":"source-code","pinned_version":{"ref":"abc123"}}`))
+				}))
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/tools/versions/33/pin", recordRequest)
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/tools/unpin", recordRequest)
+
+				_, err := out.NewOuterUsingClient(request, fakeclient).Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("leaves it pinned, and only unpins what it pinned itself", func() {
+				gt.Expect(requests).To(gomega.Equal([]string{
+					"PUT /api/v1/teams/team/pipelines/pipeline/resources/tools/versions/33/pin",
+					"PUT /api/v1/teams/team/pipelines/pipeline/resources/tools/unpin",
+				}))
+				gt.Expect(faketeam.CreateJobBuildCallCount()).To(gomega.Equal(1))
+			})
+		}, spec.Nested())
+
+		when("an input is already pinned to a different version", func() {
+			var err error
+
+			it.Before(func() {
+				server.RouteToHandler("GET", "/api/v1/teams/team/pipelines/pipeline/resources/tools", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`{"name":"tools","pinned_version":{"version":"1.0.0"}}`))
+				}))
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin", recordRequest)
+
+				_, err = out.NewOuterUsingClient(request, fakeclient).Out()
+			})
+
+			it("fails without pinning or triggering anything", func() {
+				gt.Expect(err).To(gomega.MatchError("resource 'tools' in pipeline 'pipeline' is pinned to map[version:1.0.0], not map[version:1.2.3] as used by build '999', so the build can't be rerun until it is unpinned"))
+				gt.Expect(requests).To(gomega.BeEmpty())
+				gt.Expect(faketeam.CreateJobBuildCallCount()).To(gomega.Equal(0))
+			})
+		}, spec.Nested())
+
+		when("an input can't be pinned", func() {
+			var err error
+
+			it.Before(func() {
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin", recordRequest)
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/tools/versions/33/pin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+				}))
+				server.RouteToHandler("PUT", "/api/v1/teams/team/pipelines/pipeline/resources/source-code/unpin", recordRequest)
+
+				_, err = out.NewOuterUsingClient(request, fakeclient).Out()
+			})
+
+			it("unpins whatever was already pinned and does not trigger the job", func() {
+				gt.Expect(err.Error()).To(gomega.ContainSubstring("could not pin resource 'tools' in pipeline 'pipeline' to rerun build '999'"))
+				gt.Expect(requests).To(gomega.Equal([]string{
+					"PUT /api/v1/teams/team/pipelines/pipeline/resources/source-code/versions/22/pin",
+					"PUT /api/v1/teams/team/pipelines/pipeline/resources/source-code/unpin",
+				}))
+				gt.Expect(faketeam.CreateJobBuildCallCount()).To(gomega.Equal(0))
+			})
+		}, spec.Nested())
+
+		when("the build is a one-off build", func() {
+			var err error
+
+			it.Before(func() {
+				fakeclient.BuildStub = nil
+				fakeclient.BuildReturns(atc.Build{ID: 999, Name: "999", Status: "failed", TeamName: "team"}, true, nil)
+
+				_, err = out.NewOuterUsingClient(request, fakeclient).Out()
+			})
+
+			it("returns an error", func() {
+				gt.Expect(err.Error()).To(gomega.Equal("build '999' is a one-off build, which has no job to rerun"))
+			})
+		}, spec.Nested())
+
+		when("params.build is not set", func() {
+			var err error
+
+			it.Before(func() {
+				request.Params.Build = ""
+				_, err = out.NewOuterUsingClient(request, fakeclient).Out()
+			})
+
+			it("returns an error", func() {
+				gt.Expect(err.Error()).To(gomega.Equal("build must be set in params to rerun a build"))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
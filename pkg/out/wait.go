@@ -0,0 +1,117 @@
+package out
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/eventstream"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/buildevents"
+
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const defaultWaitTimeout = time.Hour
+
+// How often to check on a build whose events can't be followed, or which hasn't finished when its events end.
+var waitPollInterval = 5 * time.Second
+
+// waitTimeout parses params.wait_timeout, which is checked before anything is triggered so that a typo doesn't
+// leave an unwatched build behind.
+func (o outer) waitTimeout() (time.Duration, error) {
+	waitTimeout := o.outRequest.Params.WaitTimeout
+	if waitTimeout == "" {
+		return defaultWaitTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(waitTimeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("could not parse wait_timeout '%s', expected a duration like '30m'", waitTimeout)
+	}
+
+	return timeout, nil
+}
+
+// waitIfAsked follows a triggered build until it finishes when params.wait is set, rendering its logs into the
+// output of the put, and returns the finished build. The put fails unless the build succeeded.
+func (o outer) waitIfAsked(build atc.Build) (atc.Build, error) {
+	if !o.outRequest.Params.Wait {
+		return build, nil
+	}
+
+	timeout, err := o.waitTimeout()
+	if err != nil {
+		return atc.Build{}, err
+	}
+	deadline := time.Now().Add(timeout)
+
+	fmt.Fprintf(o.logs, "waiting for %s\n", o.buildUrl(build))
+	err = o.renderEventsUntil(build, deadline)
+	if err != nil {
+		return atc.Build{}, err
+	}
+
+	finished, err := o.waitUntilFinished(build, deadline)
+	if err != nil {
+		return atc.Build{}, err
+	}
+
+	if finished.Status != string(atc.StatusSucceeded) {
+		return atc.Build{}, fmt.Errorf("build %s finished with status '%s'", o.buildUrl(finished), finished.Status)
+	}
+
+	return finished, nil
+}
+
+// renderEventsUntil renders the build's events as fly would, until the build ends or the deadline passes.
+func (o outer) renderEventsUntil(build atc.Build, deadline time.Time) error {
+	buildId := strconv.Itoa(build.ID)
+	events, authorized, err := buildevents.Open(o.concourseClient, buildId)
+	if err != nil {
+		return err
+	}
+	if !authorized {
+		fmt.Fprintf(o.logs, "was unauthorized to fetch events for build '%s', no logs will be shown.\n", buildId)
+		return nil
+	}
+	defer events.Close()
+
+	rendered := make(chan int, 1)
+	go func() {
+		rendered <- eventstream.Render(o.logs, events)
+	}()
+
+	select {
+	case <-rendered:
+		return nil
+	case <-time.After(time.Until(deadline)):
+		return o.timedOut(build)
+	}
+}
+
+// waitUntilFinished confirms the build's final status, which the event stream may not have reached.
+func (o outer) waitUntilFinished(build atc.Build, deadline time.Time) (atc.Build, error) {
+	buildId := strconv.Itoa(build.ID)
+
+	for {
+		current, found, err := o.concourseClient.Build(buildId)
+		if err != nil {
+			return atc.Build{}, fmt.Errorf("error while fetching build '%s': %s", buildId, err.Error())
+		}
+		if !found {
+			return atc.Build{}, fmt.Errorf("server could not find build '%s'", buildId)
+		}
+		if current.Status != string(atc.StatusStarted) && current.Status != string(atc.StatusPending) {
+			return current, nil
+		}
+
+		if time.Now().After(deadline) {
+			return atc.Build{}, o.timedOut(build)
+		}
+		time.Sleep(waitPollInterval)
+	}
+}
+
+func (o outer) timedOut(build atc.Build) error {
+	timeout, _ := o.waitTimeout()
+	return fmt.Errorf("build %s had not finished after waiting %s, it has been left running", o.buildUrl(build), timeout)
+}
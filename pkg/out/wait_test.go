@@ -0,0 +1,137 @@
+package out_test
+
+import (
+	fakes "github.com/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/concourse/go-concourse/concourse/eventstream/eventstreamfakes"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/config"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/out"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+
+	"errors"
+	"io"
+)
+
+func TestWait(t *testing.T) {
+	spec.Run(t, "wait", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+		var faketeam *fakes.FakeTeam
+		var fakeclient *fakes.FakeClient
+		var fakeeventstream *eventstreamfakes.FakeEventStream
+		var request *config.OutRequest
+		var response *config.OutResponse
+		var err error
+
+		triggered := atc.Build{ID: 999, Name: "111", Status: "pending", TeamName: "team", PipelineName: "pipeline", JobName: "job"}
+		finishedWith := func(status atc.BuildStatus) atc.Build {
+			finished := triggered
+			finished.Status = string(status)
+			return finished
+		}
+
+		it.Before(func() {
+			faketeam = new(fakes.FakeTeam)
+			faketeam.CreateJobBuildReturns(triggered, nil)
+
+			fakeeventstream = new(eventstreamfakes.FakeEventStream)
+			fakeeventstream.NextEventReturnsOnCall(0, event.Log{Payload: "hello from the other side\n"}, nil)
+			fakeeventstream.NextEventReturnsOnCall(1, event.Status{Status: atc.StatusSucceeded}, nil)
+			fakeeventstream.NextEventReturns(nil, io.EOF)
+
+			fakeclient = new(fakes.FakeClient)
+			fakeclient.TeamReturns(faketeam)
+			fakeclient.BuildEventsReturns(fakeeventstream, nil)
+			fakeclient.BuildReturns(finishedWith(atc.StatusSucceeded), true, nil)
+
+			request = &config.OutRequest{
+				Source: config.Source{ConcourseUrl: "https://example.com", Team: "team", Pipeline: "pipeline", Job: "job"},
+				Params: config.OutParams{Action: "trigger", Wait: true},
+			}
+		})
+
+		when("the triggered build succeeds", func() {
+			it.Before(func() {
+				response, err = out.NewOuterUsingClient(request, fakeclient).Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("follows the build's events", func() {
+				gt.Expect(fakeclient.BuildEventsArgsForCall(0)).To(gomega.Equal("999"))
+				gt.Expect(fakeeventstream.NextEventCallCount()).To(gomega.Equal(2))
+				gt.Expect(fakeeventstream.CloseCallCount()).To(gomega.Equal(1))
+			})
+
+			it("includes the final status in the version", func() {
+				gt.Expect(response.Version).To(gomega.Equal(config.Version{
+					BuildId:   "999",
+					Team:      "team",
+					Pipeline:  "pipeline",
+					Job:       "job",
+					JobNumber: "111",
+					Status:    "succeeded",
+				}))
+			})
+		}, spec.Nested())
+
+		when("the triggered build fails", func() {
+			it.Before(func() {
+				fakeeventstream.NextEventReturnsOnCall(1, event.Status{Status: atc.StatusFailed}, nil)
+				fakeclient.BuildReturns(finishedWith(atc.StatusFailed), true, nil)
+
+				response, err = out.NewOuterUsingClient(request, fakeclient).Out()
+			})
+
+			it("returns an error", func() {
+				gt.Expect(err).To(gomega.MatchError("build https://example.com/teams/team/pipelines/pipeline/jobs/job/builds/111 finished with status 'failed'"))
+			})
+		}, spec.Nested())
+
+		when("the events aren't visible", func() {
+			it.Before(func() {
+				fakeclient.BuildEventsReturns(nil, errors.New("not authorized"))
+
+				response, err = out.NewOuterUsingClient(request, fakeclient).Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("still waits for the build to finish", func() {
+				gt.Expect(fakeclient.BuildArgsForCall(0)).To(gomega.Equal("999"))
+				gt.Expect(response.Version.Status).To(gomega.Equal("succeeded"))
+			})
+		}, spec.Nested())
+
+		when("wait_timeout can't be parsed", func() {
+			it.Before(func() {
+				request.Params.WaitTimeout = "a while"
+
+				response, err = out.NewOuterUsingClient(request, fakeclient).Out()
+			})
+
+			it("returns an error before triggering anything", func() {
+				gt.Expect(err).To(gomega.MatchError("could not parse wait_timeout 'a while', expected a duration like '30m'"))
+				gt.Expect(faketeam.CreateJobBuildCallCount()).To(gomega.Equal(0))
+			})
+		}, spec.Nested())
+
+		when("wait is not set", func() {
+			it.Before(func() {
+				request.Params.Wait = false
+
+				response, err = out.NewOuterUsingClient(request, fakeclient).Out()
+				gt.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("returns as soon as the build is triggered", func() {
+				gt.Expect(fakeclient.BuildEventsCallCount()).To(gomega.Equal(0))
+				gt.Expect(response.Version.Status).To(gomega.BeEmpty())
+				gt.Expect(response.Metadata).To(gomega.ContainElement(config.VersionMetadataField{Name: "status", Value: "pending"}))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,95 @@
+package timings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Table reads timings.json and lays it out as a table, with a row for each step in the order they started. Times
+// are shown from the start of the build.
+func Table(jsonpath string) (string, error) {
+	cleanpath := filepath.Clean(jsonpath)
+	if strings.HasPrefix(cleanpath, "/") ||
+		strings.Contains(cleanpath, "..") ||
+		strings.Count(cleanpath, "/") > 1 {
+		return "", fmt.Errorf("malformed path")
+	}
+
+	jsonFile, err := os.Open(cleanpath)
+	if err != nil {
+		return "", fmt.Errorf("could not open %s: %s", cleanpath, err.Error())
+	}
+	defer jsonFile.Close()
+
+	var timings Timings
+	err = json.NewDecoder(jsonFile).Decode(&timings)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s: %s", cleanpath, err.Error())
+	}
+
+	return FormatTable(timings), nil
+}
+
+// FormatTable lays out timings as a table. Estimated times are marked with a '~'.
+func FormatTable(timings Timings) string {
+	// without a start for the build, times are from whichever step started first
+	start := timings.Build.StartTime
+	for _, step := range timings.Steps {
+		if timings.Build.StartTime == 0 && (start == 0 || step.StartTime < start) {
+			start = step.StartTime
+		}
+	}
+
+	builder := &strings.Builder{}
+	table := tabwriter.NewWriter(builder, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "STEP\tSTARTED\tDURATION\t% OF BUILD\tEXIT STATUS")
+
+	steps := append([]StepTiming{}, timings.Steps...)
+	sort.SliceStable(steps, func(a, b int) bool { return steps[a].StartTime < steps[b].StartTime })
+
+	for _, step := range steps {
+		name := step.ID
+		if step.Name != "" {
+			name = fmt.Sprintf("%s: %s", step.Kind, step.Name)
+		}
+
+		estimated := ""
+		if step.Estimated {
+			estimated = "~"
+		}
+
+		share := "-"
+		if timings.Build.DurationSeconds > 0 {
+			share = fmt.Sprintf("%.1f%%", 100*float64(step.DurationSeconds)/float64(timings.Build.DurationSeconds))
+		}
+
+		exitStatus := "-"
+		if step.ExitStatus != nil {
+			exitStatus = strconv.Itoa(*step.ExitStatus)
+		}
+
+		fmt.Fprintf(table, "%s\t+%s\t%s%s\t%s\t%s\n", name, clock(step.StartTime-start), estimated, clock(step.DurationSeconds), share, exitStatus)
+	}
+
+	if timings.Build.DurationSeconds > 0 {
+		fmt.Fprintf(table, "build\t+%s\t%s\t100.0%%\t-\n", clock(0), clock(timings.Build.DurationSeconds))
+	}
+
+	table.Flush()
+	return builder.String()
+}
+
+// clock is a number of seconds as hours, minutes and seconds, like 01:02:05.
+func clock(seconds int64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
@@ -0,0 +1,159 @@
+package timings
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+// Timings is the contents of timings.json. Times are in seconds since the Unix epoch, as in build.json.
+type Timings struct {
+	Build BuildTiming  `json:"build"`
+	Steps []StepTiming `json:"steps"`
+}
+
+type BuildTiming struct {
+	StartTime       int64 `json:"start_time,omitempty"`
+	EndTime         int64 `json:"end_time,omitempty"`
+	DurationSeconds int64 `json:"duration_seconds,omitempty"`
+}
+
+// StepTiming is when a step started and ended. For tasks these come from the events Concourse sends when a task is
+// initialized, starts running and finishes, and RunTime is when it started running, after its image was fetched.
+// Gets and puts have no such events, so their times are those of their first and last lines of output, and are
+// marked as Estimated.
+type StepTiming struct {
+	ID              string `json:"id"`
+	Kind            string `json:"kind,omitempty"`
+	Name            string `json:"name,omitempty"`
+	StartTime       int64  `json:"start_time"`
+	RunTime         int64  `json:"run_time,omitempty"`
+	EndTime         int64  `json:"end_time"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	ExitStatus      *int   `json:"exit_status,omitempty"`
+	Estimated       bool   `json:"estimated,omitempty"`
+}
+
+// Recorder works out timings from a build's events, as they are read.
+type Recorder struct {
+	names map[event.OriginID]StepName
+	steps map[event.OriginID]*stepEvents
+	order []event.OriginID
+	build BuildTiming
+}
+
+// StepName is the kind of a step, such as get or task, and its name in the plan.
+type StepName struct {
+	Kind string
+	Name string
+}
+
+type stepEvents struct {
+	firstTime      int64
+	lastTime       int64
+	initializeTime int64
+	startTime      int64
+	finishTime     int64
+	exitStatus     *int
+}
+
+// NewRecorder names steps from the plan. Steps which aren't named are only known by their ID.
+func NewRecorder(names map[event.OriginID]StepName) *Recorder {
+	return &Recorder{
+		names: names,
+		steps: make(map[event.OriginID]*stepEvents),
+	}
+}
+
+func (r *Recorder) Record(ev atc.Event) {
+	switch e := ev.(type) {
+	case event.Status:
+		if e.Status == atc.StatusStarted {
+			r.build.StartTime = e.Time
+		} else {
+			r.build.EndTime = e.Time
+		}
+	case event.InitializeTask:
+		r.step(e.Origin, e.Time).initializeTime = e.Time
+	case event.StartTask:
+		r.step(e.Origin, e.Time).startTime = e.Time
+	case event.FinishTask:
+		step := r.step(e.Origin, e.Time)
+		step.finishTime = e.Time
+		exitStatus := e.ExitStatus
+		step.exitStatus = &exitStatus
+	case event.Log:
+		r.step(e.Origin, e.Time)
+	case event.FinishGet:
+		exitStatus := e.ExitStatus
+		r.step(e.Origin, 0).exitStatus = &exitStatus
+	case event.FinishPut:
+		exitStatus := e.ExitStatus
+		r.step(e.Origin, 0).exitStatus = &exitStatus
+	}
+}
+
+func (r *Recorder) step(origin event.Origin, time int64) *stepEvents {
+	// events from the build as a whole aren't from any step
+	if origin.ID == "" {
+		return &stepEvents{}
+	}
+
+	step, ok := r.steps[origin.ID]
+	if !ok {
+		step = &stepEvents{}
+		r.steps[origin.ID] = step
+		r.order = append(r.order, origin.ID)
+	}
+
+	if time != 0 {
+		if step.firstTime == 0 || time < step.firstTime {
+			step.firstTime = time
+		}
+		if time > step.lastTime {
+			step.lastTime = time
+		}
+	}
+
+	return step
+}
+
+// Timings has every step which has had a timed event so far, in the order they were first heard from.
+func (r *Recorder) Timings() Timings {
+	timings := Timings{Build: r.build, Steps: []StepTiming{}}
+	if timings.Build.StartTime != 0 && timings.Build.EndTime >= timings.Build.StartTime {
+		timings.Build.DurationSeconds = timings.Build.EndTime - timings.Build.StartTime
+	} else {
+		timings.Build.EndTime = 0
+	}
+
+	for _, id := range r.order {
+		step := r.steps[id]
+		if step.firstTime == 0 {
+			// nothing from this step has said when it happened
+			continue
+		}
+
+		name := r.names[id]
+		timing := StepTiming{
+			ID:         string(id),
+			Kind:       name.Kind,
+			Name:       name.Name,
+			StartTime:  step.firstTime,
+			RunTime:    step.startTime,
+			EndTime:    step.lastTime,
+			ExitStatus: step.exitStatus,
+			Estimated:  step.initializeTime == 0 || step.finishTime == 0,
+		}
+		if step.initializeTime != 0 {
+			timing.StartTime = step.initializeTime
+		}
+		if step.finishTime != 0 {
+			timing.EndTime = step.finishTime
+		}
+		timing.DurationSeconds = timing.EndTime - timing.StartTime
+
+		timings.Steps = append(timings.Steps, timing)
+	}
+
+	return timings
+}
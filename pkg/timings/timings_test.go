@@ -0,0 +1,110 @@
+package timings_test
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/jchesterpivotal/concourse-build-resource/pkg/timings"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"testing"
+)
+
+func TestTimings(t *testing.T) {
+	spec.Run(t, "timings", func(t *testing.T, when spec.G, it spec.S) {
+		gt := gomega.NewGomegaWithT(t)
+
+		// 2018-09-01T12:00:00Z
+		const buildStart = 1535803200
+		zero, one := 0, 1
+
+		when("recording a build's events", func() {
+			var recorded timings.Timings
+
+			it.Before(func() {
+				recorder := timings.NewRecorder(map[event.OriginID]timings.StepName{
+					"g1": {Kind: "get", Name: "source-code"},
+					"t1": {Kind: "task", Name: "unit"},
+					"t2": {Kind: "task", Name: "never-ran"},
+				})
+
+				for _, ev := range []atc.Event{
+					event.Status{Status: atc.StatusStarted, Time: buildStart},
+					event.Log{Origin: event.Origin{ID: "g1"}, Time: buildStart + 1, Payload: "cloning\n"},
+					event.Log{Origin: event.Origin{ID: "g1"}, Time: buildStart + 9, Payload: "cloned\n"},
+					event.FinishGet{Origin: event.Origin{ID: "g1"}, ExitStatus: 0},
+					event.InitializeTask{Origin: event.Origin{ID: "t1"}, Time: buildStart + 10},
+					event.StartTask{Origin: event.Origin{ID: "t1"}, Time: buildStart + 40},
+					event.Log{Origin: event.Origin{ID: "t1"}, Time: buildStart + 50, Payload: "FAIL\n"},
+					event.FinishTask{Origin: event.Origin{ID: "t1"}, Time: buildStart + 100, ExitStatus: 1},
+					event.Log{Origin: event.Origin{ID: "x1"}, Time: buildStart + 105, Payload: "not in the plan\n"},
+					event.Error{Message: "build-wide error"},
+					event.Status{Status: atc.StatusFailed, Time: buildStart + 120},
+				} {
+					recorder.Record(ev)
+				}
+
+				recorded = recorder.Timings()
+			})
+
+			it("has the build's start, end and duration", func() {
+				gt.Expect(recorded.Build).To(gomega.Equal(timings.BuildTiming{StartTime: buildStart, EndTime: buildStart + 120, DurationSeconds: 120}))
+			})
+
+			it("times tasks from when they were initialized until they finished", func() {
+				gt.Expect(recorded.Steps[1]).To(gomega.Equal(timings.StepTiming{
+					ID: "t1", Kind: "task", Name: "unit",
+					StartTime: buildStart + 10, RunTime: buildStart + 40, EndTime: buildStart + 100, DurationSeconds: 90,
+					ExitStatus: &one,
+				}))
+			})
+
+			it("estimates gets and puts from their first and last lines of output", func() {
+				gt.Expect(recorded.Steps[0]).To(gomega.Equal(timings.StepTiming{
+					ID: "g1", Kind: "get", Name: "source-code",
+					StartTime: buildStart + 1, EndTime: buildStart + 9, DurationSeconds: 8,
+					ExitStatus: &zero, Estimated: true,
+				}))
+			})
+
+			it("has steps which aren't in the plan, but not those which never ran", func() {
+				gt.Expect(recorded.Steps).To(gomega.HaveLen(3))
+				gt.Expect(recorded.Steps[2].ID).To(gomega.Equal("x1"))
+				gt.Expect(recorded.Steps[2].Name).To(gomega.BeEmpty())
+			})
+		}, spec.Nested())
+
+		when("the build is still running", func() {
+			it("has no end or duration for the build", func() {
+				recorder := timings.NewRecorder(nil)
+				recorder.Record(event.Status{Status: atc.StatusStarted, Time: buildStart})
+
+				gt.Expect(recorder.Timings()).To(gomega.Equal(timings.Timings{
+					Build: timings.BuildTiming{StartTime: buildStart},
+					Steps: []timings.StepTiming{},
+				}))
+			})
+		}, spec.Nested())
+
+		when("formatting a table", func() {
+			it("has a row for each step in the order they started, and one for the build", func() {
+				table := timings.FormatTable(timings.Timings{
+					Build: timings.BuildTiming{StartTime: buildStart, EndTime: buildStart + 200, DurationSeconds: 200},
+					Steps: []timings.StepTiming{
+						{ID: "t1", Kind: "task", Name: "unit", StartTime: buildStart + 10, EndTime: buildStart + 110, DurationSeconds: 100, ExitStatus: &one},
+						{ID: "g1", Kind: "get", Name: "source-code", StartTime: buildStart + 1, EndTime: buildStart + 9, DurationSeconds: 8, Estimated: true},
+						{ID: "x1", StartTime: buildStart + 3725, EndTime: buildStart + 3725},
+					},
+				})
+
+				gt.Expect(table).To(gomega.Equal("" +
+					"STEP              STARTED    DURATION   % OF BUILD  EXIT STATUS\n" +
+					"get: source-code  +00:00:01  ~00:00:08  4.0%        -\n" +
+					"task: unit        +00:00:10  00:01:40   50.0%       1\n" +
+					"x1                +01:02:05  00:00:00   0.0%        -\n" +
+					"build             +00:00:00  00:03:20   100.0%      -\n",
+				))
+			})
+		}, spec.Nested())
+	}, spec.Report(report.Terminal{}))
+}